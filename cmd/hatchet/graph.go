@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/diagram"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+var graphCmd = &command{
+	name:        "graph",
+	usage:       "graph [--dir ./.hatchet] [--format text|mermaid|dot] [--file name] [--out dir]",
+	description: "Print the jobs and steps of every workflow file in the order they run, as text or as a diagram.",
+	run:         runGraph,
+}
+
+func runGraph(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+	format := fs.String("format", "text", "the output format: text, mermaid or dot")
+	fileName := fs.String("file", "", "only print the workflow file with this name")
+	outDir := fs.String("out", "", "write a diagram for each workflow file to this folder instead of printing it")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != "text" && *format != string(diagram.FormatMermaid) && *format != string(diagram.FormatDOT) {
+		return fmt.Errorf("unsupported format %q: must be text, mermaid or dot", *format)
+	}
+
+	if *outDir != "" && *format == "text" {
+		return fmt.Errorf("--out requires --format mermaid or dot")
+	}
+
+	files, err := fileutils.ReadAllFilesInDir(*dir)
+
+	if err != nil {
+		printProblems(err)
+		return errProblemsFound
+	}
+
+	if *fileName != "" {
+		files = filterFiles(files, *fileName)
+
+		if len(files) == 0 {
+			return fmt.Errorf("no workflow file named %q in %s", *fileName, *dir)
+		}
+	}
+
+	if *format == "text" {
+		for i, file := range files {
+			if i > 0 {
+				fmt.Println()
+			}
+
+			if err := printGraph(os.Stdout, file); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for i, file := range files {
+		out, err := diagram.Render(file, diagram.Format(*format))
+
+		if err != nil {
+			return fmt.Errorf("could not render %s: %w", file.Name, err)
+		}
+
+		if *outDir != "" {
+			if err := writeDiagram(*outDir, file, *format, out); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+
+		fmt.Print(out)
+	}
+
+	return nil
+}
+
+func filterFiles(files []*types.WorkflowFile, name string) []*types.WorkflowFile {
+	res := []*types.WorkflowFile{}
+
+	for _, file := range files {
+		if file.Name == name {
+			res = append(res, file)
+		}
+	}
+
+	return res
+}
+
+// writeDiagram writes the diagram of a workflow file to the output folder, named after the workflow file, for
+// example .hatchet/user-signup.yaml is written to user-signup.mmd or user-signup.dot.
+func writeDiagram(outDir string, file *types.WorkflowFile, format, out string) error {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	ext := ".dot"
+
+	if format == string(diagram.FormatMermaid) {
+		ext = ".mmd"
+	}
+
+	base := filepath.Base(file.FilePath())
+	outPath := filepath.Join(outDir, strings.TrimSuffix(base, filepath.Ext(base))+ext)
+
+	if err := os.WriteFile(outPath, []byte(out), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Println(outPath)
+
+	return nil
+}
+
+// printGraph prints the jobs of a workflow file in topological order, with the steps of each job indented
+// beneath it.
+func printGraph(w io.Writer, file *types.WorkflowFile) error {
+	tree, err := types.ParseWorkflowTreeFromFile(*file)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s (%s)\n", file.Name, file.FilePath())
+
+	if len(file.On.Events) > 0 {
+		events := make([]string, 0, len(file.On.Events))
+
+		for _, event := range file.On.Events {
+			events = append(events, event.String())
+		}
+
+		fmt.Fprintf(w, "  on events: %s\n", strings.Join(events, ", "))
+	}
+
+	for _, schedule := range file.On.ListSchedules() {
+		fmt.Fprintf(w, "  on cron: %s\n", schedule.String())
+	}
+
+	for _, node := range tree.Nodes() {
+		job := file.Jobs[node.Name]
+
+		fmt.Fprintf(w, "  job %s%s\n", node.Name, describeJob(job))
+
+		for _, step := range job.Steps {
+			if !step.IsParallel() {
+				printStep(w, "    ", step)
+				continue
+			}
+
+			fmt.Fprintln(w, "    parallel")
+
+			for _, groupStep := range step.Parallel {
+				printStep(w, "      ", groupStep)
+			}
+		}
+
+		if len(job.OnFailure) > 0 {
+			fmt.Fprintln(w, "    on failure")
+
+			for _, step := range job.OnFailure {
+				printStep(w, "      ", step)
+			}
+		}
+	}
+
+	return nil
+}
+
+func describeJob(job types.WorkflowJob) string {
+	details := []string{}
+
+	if len(job.Needs) > 0 {
+		details = append(details, "needs "+strings.Join(job.Needs, ", "))
+	}
+
+	if job.If != "" {
+		details = append(details, "if "+job.If)
+	}
+
+	if len(details) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(details, "; ") + ")"
+}
+
+func printStep(w io.Writer, indent string, step types.WorkflowStep) {
+	details := []string{step.ActionID}
+
+	if step.If != "" {
+		details = append(details, "if "+step.If)
+	}
+
+	if step.ForEach != nil {
+		details = append(details, "for each in "+step.ForEach.Items)
+	}
+
+	fmt.Fprintf(w, "%sstep %s: %s\n", indent, stepLabel(step), strings.Join(details, "; "))
+
+	if step.Compensate != nil {
+		fmt.Fprintf(w, "%s  compensate: %s\n", indent, step.Compensate.ActionID)
+	}
+}
+
+// stepLabel returns the id of a step, or its name if it has no id.
+func stepLabel(step types.WorkflowStep) string {
+	if step.ID != "" {
+		return step.ID
+	}
+
+	return fmt.Sprintf("%q", step.Name)
+}
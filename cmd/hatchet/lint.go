@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
+)
+
+var lintCmd = &command{
+	name:        "lint",
+	usage:       "lint [--dir ./.hatchet] [--strict]",
+	description: "Validate every workflow file and warn about likely mistakes, like steps without timeouts. Warnings only fail the command with --strict.",
+	run:         runLint,
+}
+
+func runLint(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+	strict := fs.Bool("strict", false, "exit with a non-zero status if there are any warnings")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := fileutils.ReadAllFilesInDir(*dir)
+
+	if err != nil {
+		printProblems(err)
+		return errProblemsFound
+	}
+
+	var warnings error
+
+	for _, file := range files {
+		if err := file.Lint(); err != nil {
+			warnings = multierror.Append(warnings, err)
+		}
+	}
+
+	if warnings == nil {
+		fmt.Printf("%d workflow files have no problems\n", len(files))
+		return nil
+	}
+
+	printProblems(warnings)
+
+	if *strict {
+		return errProblemsFound
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// Command hatchet validates and inspects the workflow files in a .hatchet folder, triggers and manages their runs
+// and schedules, and manages local encrypted secrets.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// errProblemsFound is returned by commands which have already printed the problems they found, so that the
+// command exits with a non-zero status without printing anything else.
+var errProblemsFound = errors.New("problems found")
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(cmd *command, args []string) error
+}
+
+var commands = []*command{
+	validateCmd,
+	lintCmd,
+	graphCmd,
+	triggerCmd,
+	runsCmd,
+	schedulesCmd,
+	secretsCmd,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		err := cmd.run(cmd, os.Args[2:])
+
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, flag.ErrHelp):
+			return
+		case errors.Is(err, errProblemsFound):
+			os.Exit(1)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage()
+	os.Exit(2)
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: hatchet <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run `hatchet <command> -h` for the flags of a command.")
+}
+
+// newFlagSet returns the flags for a command, including the --dir flag which sets the folder workflow files
+// are read from.
+func newFlagSet(cmd *command) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: hatchet %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.description)
+		fs.PrintDefaults()
+	}
+
+	dir := fs.String("dir", "./.hatchet", "the folder containing workflow files")
+
+	return fs, dir
+}
+
+// parseInterspersed parses flags which can appear before or after positional arguments, and returns the
+// positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printProblems prints each error combined in err on its own line, prefixing lint warnings with "warning: ".
+func printProblems(err error) {
+	var multiErr *multierror.Error
+
+	if !errors.As(err, &multiErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, problem := range multiErr.Errors {
+		prefix := ""
+
+		var validationErr *types.ValidationError
+
+		if errors.As(problem, &validationErr) && validationErr.Warning {
+			prefix = "warning: "
+		}
+
+		fmt.Fprintln(os.Stderr, prefix+strings.TrimSpace(problem.Error()))
+	}
+}
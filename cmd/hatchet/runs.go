@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/dispatcher"
+)
+
+var runsCmd = &command{
+	name:        "runs",
+	usage:       "runs list|describe|cancel|terminate [flags]",
+	description: "List, inspect and stop the runs of workflow files.",
+	run:         runRuns,
+}
+
+var runsSubcommands = []*command{
+	{
+		name:        "list",
+		usage:       "runs list [--file name] [--job name] [--event name] [--limit 20] [--dir ./.hatchet]",
+		description: "List the runs of workflow files, most recent first.",
+		run:         runRunsList,
+	},
+	{
+		name:        "describe",
+		usage:       "runs describe <workflow-id> [--run-id id] [--dir ./.hatchet]",
+		description: "Print the status, pending steps and results of a run.",
+		run:         runRunsDescribe,
+	},
+	{
+		name:        "cancel",
+		usage:       "runs cancel <workflow-id> [--run-id id] [--dir ./.hatchet]",
+		description: "Cancel a run. Failure handlers of the running jobs still run.",
+		run:         runRunsCancel,
+	},
+	{
+		name:        "terminate",
+		usage:       "runs terminate <workflow-id> [--run-id id] [--reason text] [--dir ./.hatchet]",
+		description: "Stop a run immediately, without running failure handlers.",
+		run:         runRunsTerminate,
+	},
+}
+
+func runRuns(cmd *command, args []string) error {
+	if len(args) > 0 {
+		for _, subcommand := range runsSubcommands {
+			if subcommand.name == args[0] {
+				return subcommand.run(subcommand, args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Usage: hatchet %s\n\nCommands:\n", cmd.usage)
+
+	for _, subcommand := range runsSubcommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", subcommand.name, subcommand.description)
+	}
+
+	return errProblemsFound
+}
+
+func runRunsList(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	filter := dispatcher.RunFilter{}
+
+	fs.StringVar(&filter.WorkflowFile, "file", "", "only list runs of the workflow file with this name")
+	fs.StringVar(&filter.Job, "job", "", "only list runs of this job")
+	fs.StringVar(&filter.Event, "event", "", "only list runs triggered by this event")
+	fs.IntVar(&filter.Limit, "limit", 20, "the maximum number of runs to list, or 0 for every run")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	runs, err := d.ListRuns(context.Background(), filter)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "WORKFLOW ID\tRUN ID\tWORKFLOW FILE\tJOB\tEVENT\tSTATUS\tSTARTED")
+
+	for _, run := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", run.WorkflowID, run.RunID, run.WorkflowFile, orDash(run.Job),
+			orDash(run.Event), run.Status, run.StartTime.Local().Format(time.RFC3339))
+	}
+
+	return w.Flush()
+}
+
+func runRunsDescribe(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	runID := fs.String("run-id", "", "the run to describe, defaults to the latest run of the workflow")
+
+	workflowID, err := parseWorkflowID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	run, err := d.DescribeRun(context.Background(), workflowID, *runID)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Workflow ID:\t%s\n", run.WorkflowID)
+	fmt.Fprintf(w, "Run ID:\t%s\n", run.RunID)
+	fmt.Fprintf(w, "Workflow file:\t%s\n", run.WorkflowFile)
+	fmt.Fprintf(w, "Job:\t%s\n", orDash(run.Job))
+	fmt.Fprintf(w, "Event:\t%s\n", orDash(run.Event))
+	fmt.Fprintf(w, "Status:\t%s\n", run.Status)
+	fmt.Fprintf(w, "Started:\t%s\n", run.StartTime.Local().Format(time.RFC3339))
+
+	if !run.CloseTime.IsZero() {
+		fmt.Fprintf(w, "Closed:\t%s\n", run.CloseTime.Local().Format(time.RFC3339))
+	}
+
+	for _, step := range run.PendingSteps {
+		fmt.Fprintf(w, "Pending step:\t%s (attempt %d)\n", step.ActionID, step.Attempt)
+
+		if step.LastFailure != "" {
+			fmt.Fprintf(w, "\tlast failure: %s\n", step.LastFailure)
+		}
+	}
+
+	for _, job := range run.PendingJobs {
+		fmt.Fprintf(w, "Pending job:\t%s\n", job)
+	}
+
+	if run.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", run.Error)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if run.Results != nil {
+		resultBytes, err := json.MarshalIndent(run.Results, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Results:\n%s\n", resultBytes)
+	}
+
+	if run.Outputs != nil {
+		outputBytes, err := json.MarshalIndent(run.Outputs, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Outputs:\n%s\n", outputBytes)
+	}
+
+	return nil
+}
+
+func runRunsCancel(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	runID := fs.String("run-id", "", "the run to cancel, defaults to the latest run of the workflow")
+
+	workflowID, err := parseWorkflowID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := d.CancelRun(context.Background(), workflowID, *runID); err != nil {
+		return err
+	}
+
+	fmt.Printf("requested cancellation of %s\n", workflowID)
+
+	return nil
+}
+
+func runRunsTerminate(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	runID := fs.String("run-id", "", "the run to terminate, defaults to the latest run of the workflow")
+	reason := fs.String("reason", "terminated with the hatchet cli", "the reason recorded in the run's history")
+
+	workflowID, err := parseWorkflowID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := d.TerminateRun(context.Background(), workflowID, *runID, *reason); err != nil {
+		return err
+	}
+
+	fmt.Printf("terminated %s\n", workflowID)
+
+	return nil
+}
+
+func parseWorkflowID(fs *flag.FlagSet, args []string) (string, error) {
+	positional, err := parseInterspersed(fs, args)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return "", errors.New("expected exactly one workflow id")
+	}
+
+	return positional[0], nil
+}
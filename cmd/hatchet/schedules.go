@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/dispatcher"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+var schedulesCmd = &command{
+	name:        "schedules",
+	usage:       "schedules list|pause|resume|trigger|backfill|reconcile [flags]",
+	description: "Manage the Temporal schedules of workflow files.",
+	run:         runSchedules,
+}
+
+var schedulesSubcommands = []*command{
+	{
+		name:        "list",
+		usage:       "schedules list [--dir ./.hatchet]",
+		description: "List the schedules managed by Hatchet, with their next run times.",
+		run:         runSchedulesList,
+	},
+	{
+		name:        "pause",
+		usage:       "schedules pause <schedule-id> [--note text] [--dir ./.hatchet]",
+		description: "Pause a schedule, with a note like the reason it was paused.",
+		run:         runSchedulesPause,
+	},
+	{
+		name:        "resume",
+		usage:       "schedules resume <schedule-id> [--note text] [--dir ./.hatchet]",
+		description: "Resume a paused schedule.",
+		run:         runSchedulesResume,
+	},
+	{
+		name:        "trigger",
+		usage:       "schedules trigger <schedule-id> [--overlap policy] [--dir ./.hatchet]",
+		description: "Start a run of a schedule immediately.",
+		run:         runSchedulesTrigger,
+	},
+	{
+		name:        "backfill",
+		usage:       "schedules backfill <schedule-id> --start time --end time [--overlap buffer_all] [--dir ./.hatchet]",
+		description: "Start the runs of a schedule which were due in a time range.",
+		run:         runSchedulesBackfill,
+	},
+	{
+		name:        "reconcile",
+		usage:       "schedules reconcile [--dry-run] [--pause] [--dir ./.hatchet]",
+		description: "Create or update the schedules of workflow files, and delete the schedules which are no longer declared.",
+		run:         runSchedulesReconcile,
+	},
+}
+
+func runSchedules(cmd *command, args []string) error {
+	if len(args) > 0 {
+		for _, subcommand := range schedulesSubcommands {
+			if subcommand.name == args[0] {
+				return subcommand.run(subcommand, args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Usage: hatchet %s\n\nCommands:\n", cmd.usage)
+
+	for _, subcommand := range schedulesSubcommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", subcommand.name, subcommand.description)
+	}
+
+	return errProblemsFound
+}
+
+func runSchedulesList(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	schedules, err := d.ListSchedules(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SCHEDULE ID\tWORKFLOW FILE\tJOB\tSTATUS\tDECLARED\tNEXT RUN\tNOTE")
+
+	for _, schedule := range schedules {
+		status := "active"
+
+		if schedule.Paused {
+			status = "paused"
+		}
+
+		declared := "no"
+
+		if schedule.Declared {
+			declared = "yes"
+		}
+
+		nextRun := "-"
+
+		if len(schedule.NextRunTimes) > 0 {
+			nextRun = schedule.NextRunTimes[0].Local().Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", schedule.ID, schedule.WorkflowFile, orDash(schedule.Job), status,
+			declared, nextRun, orDash(schedule.Note))
+	}
+
+	return w.Flush()
+}
+
+func runSchedulesPause(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	note := fs.String("note", "paused with the hatchet cli", "the note shown on the schedule, like the reason it was paused")
+
+	scheduleID, err := parseScheduleID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := d.PauseSchedule(context.Background(), scheduleID, *note); err != nil {
+		return err
+	}
+
+	fmt.Printf("paused %s\n", scheduleID)
+
+	return nil
+}
+
+func runSchedulesResume(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	note := fs.String("note", "resumed with the hatchet cli", "the note shown on the schedule")
+
+	scheduleID, err := parseScheduleID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := d.ResumeSchedule(context.Background(), scheduleID, *note); err != nil {
+		return err
+	}
+
+	fmt.Printf("resumed %s\n", scheduleID)
+
+	return nil
+}
+
+func runSchedulesTrigger(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	overlap := fs.String("overlap", "", "the overlap policy of the run, defaults to the policy of the schedule")
+
+	scheduleID, err := parseScheduleID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := d.TriggerSchedule(context.Background(), scheduleID, types.OverlapPolicy(*overlap)); err != nil {
+		return err
+	}
+
+	fmt.Printf("triggered %s\n", scheduleID)
+
+	return nil
+}
+
+func runSchedulesBackfill(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	start := fs.String("start", "", "the start of the time range, as an RFC 3339 timestamp")
+	end := fs.String("end", "", "the end of the time range, as an RFC 3339 timestamp")
+	overlap := fs.String("overlap", string(types.OverlapBufferAll), "the overlap policy of the runs, or an empty string for the policy of the schedule")
+
+	scheduleID, err := parseScheduleID(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	startTime, err := time.Parse(time.RFC3339, *start)
+
+	if err != nil {
+		return fmt.Errorf("invalid --start %q: must be an RFC 3339 timestamp like 2024-01-01T00:00:00Z", *start)
+	}
+
+	endTime, err := time.Parse(time.RFC3339, *end)
+
+	if err != nil {
+		return fmt.Errorf("invalid --end %q: must be an RFC 3339 timestamp like 2024-01-01T00:00:00Z", *end)
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	err = d.BackfillSchedule(context.Background(), scheduleID, startTime, endTime, types.OverlapPolicy(*overlap))
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backfilled %s from %s to %s\n", scheduleID, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
+
+	return nil
+}
+
+func runSchedulesReconcile(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	dryRun := fs.Bool("dry-run", false, "print the changes without changing any schedule")
+	pause := fs.Bool("pause", false, "pause schedules which are no longer declared instead of deleting them")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	opts := []dispatcher.ReconcileOptFunc{}
+
+	if *dryRun {
+		opts = append(opts, dispatcher.WithDryRun())
+	}
+
+	if *pause {
+		opts = append(opts, dispatcher.WithPauseOrphans())
+	}
+
+	diff, err := d.ReconcileSchedules(context.Background(), opts...)
+
+	if diff != nil {
+		printScheduleDiff(diff, *dryRun)
+	}
+
+	if err != nil {
+		printProblems(err)
+		return errProblemsFound
+	}
+
+	return nil
+}
+
+func printScheduleDiff(diff *dispatcher.ScheduleDiff, dryRun bool) {
+	changes := []struct {
+		verb string
+		ids  []string
+	}{
+		{"create", diff.Created},
+		{"update", diff.Updated},
+		{"recreate", diff.Recreated},
+		{"delete", diff.Deleted},
+		{"pause", diff.Paused},
+	}
+
+	for _, change := range changes {
+		for _, id := range change.ids {
+			if dryRun {
+				fmt.Printf("would %s %s\n", change.verb, id)
+			} else {
+				fmt.Printf("%sd %s\n", change.verb, id)
+			}
+		}
+	}
+}
+
+func parseScheduleID(fs *flag.FlagSet, args []string) (string, error) {
+	positional, err := parseInterspersed(fs, args)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return "", errors.New("expected exactly one schedule id")
+	}
+
+	return positional[0], nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/secrets"
+)
+
+// secretsKeyEnv is the environment variable containing the key of the encrypted secrets file.
+const secretsKeyEnv = "HATCHET_SECRETS_KEY"
+
+var secretsCmd = &command{
+	name:        "secrets",
+	usage:       "secrets keygen|set|delete|list [flags]",
+	description: "Manage the secrets in a local encrypted secrets file.",
+	run:         runSecrets,
+}
+
+var secretsSubcommands = []*command{
+	{
+		name:        "keygen",
+		usage:       "secrets keygen",
+		description: "Print a new key for an encrypted secrets file, to set as " + secretsKeyEnv + ".",
+		run:         runSecretsKeygen,
+	},
+	{
+		name:        "set",
+		usage:       "secrets set <name> [--value value] [--file path] [--dir ./.hatchet]",
+		description: "Set a secret. The value is read from stdin if --value is not set.",
+		run:         runSecretsSet,
+	},
+	{
+		name:        "delete",
+		usage:       "secrets delete <name> [--file path] [--dir ./.hatchet]",
+		description: "Delete a secret.",
+		run:         runSecretsDelete,
+	},
+	{
+		name:        "list",
+		usage:       "secrets list [--file path] [--dir ./.hatchet]",
+		description: "List the names of the secrets, without their values.",
+		run:         runSecretsList,
+	},
+}
+
+func runSecrets(cmd *command, args []string) error {
+	if len(args) > 0 {
+		for _, subcommand := range secretsSubcommands {
+			if subcommand.name == args[0] {
+				return subcommand.run(subcommand, args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Usage: hatchet %s\n\nCommands:\n", cmd.usage)
+
+	for _, subcommand := range secretsSubcommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", subcommand.name, subcommand.description)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nThe secrets file is encrypted with the key in %s.\n", secretsKeyEnv)
+
+	return errProblemsFound
+}
+
+func runSecretsKeygen(cmd *command, args []string) error {
+	fs, _ := newFlagSet(cmd)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := secrets.GenerateKey()
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(key)
+
+	return nil
+}
+
+func runSecretsSet(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+	file := secretsFileFlag(fs)
+	value := fs.String("value", "", "the value of the secret")
+
+	positional, err := parseInterspersed(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a secret name")
+	}
+
+	val := *value
+
+	if val == "" {
+		val, err = readSecretValue()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateSecrets(secretsFilePath(*dir, *file), func(values map[string]string) {
+		values[positional[0]] = val
+	})
+}
+
+func runSecretsDelete(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+	file := secretsFileFlag(fs)
+
+	positional, err := parseInterspersed(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a secret name")
+	}
+
+	return updateSecrets(secretsFilePath(*dir, *file), func(values map[string]string) {
+		delete(values, positional[0])
+	})
+}
+
+func runSecretsList(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+	file := secretsFileFlag(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := loadSecretsKey()
+
+	if err != nil {
+		return err
+	}
+
+	values, err := secrets.ReadEncryptedFile(secretsFilePath(*dir, *file), key)
+
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func secretsFileFlag(fs *flag.FlagSet) *string {
+	return fs.String("file", "", "the encrypted secrets file (defaults to secrets.enc in --dir)")
+}
+
+func secretsFilePath(dir, file string) string {
+	if file != "" {
+		return file
+	}
+
+	return filepath.Join(dir, "secrets.enc")
+}
+
+func loadSecretsKey() ([]byte, error) {
+	encoded := os.Getenv(secretsKeyEnv)
+
+	if encoded == "" {
+		return nil, fmt.Errorf("%s must be set; generate a key with `hatchet secrets keygen`", secretsKeyEnv)
+	}
+
+	return secrets.ParseKey(encoded)
+}
+
+// updateSecrets decrypts the secrets file, applies the update and writes the file back.
+func updateSecrets(path string, update func(values map[string]string)) error {
+	key, err := loadSecretsKey()
+
+	if err != nil {
+		return err
+	}
+
+	values, err := secrets.ReadEncryptedFile(path, key)
+
+	if err != nil {
+		return err
+	}
+
+	update(values)
+
+	return secrets.WriteEncryptedFile(path, key, values)
+}
+
+// readSecretValue reads a secret value from stdin, so that it isn't recorded in the shell history. From a terminal
+// a single line is read, otherwise all of stdin is read. A single trailing newline is trimmed.
+func readSecretValue() (string, error) {
+	var val string
+
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Value: ")
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+
+		if err != nil && line == "" {
+			return "", fmt.Errorf("could not read the secret value from stdin: %w", err)
+		}
+
+		val = line
+	} else {
+		data, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			return "", fmt.Errorf("could not read the secret value from stdin: %w", err)
+		}
+
+		val = string(data)
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(val, "\n"), "\r"), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/config/loader"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/dispatcher"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
+)
+
+var triggerCmd = &command{
+	name:        "trigger",
+	usage:       "trigger <event> [--data '{...}' | --data @payload.json] [--dir ./.hatchet]",
+	description: "Trigger an event, starting every workflow which listens to it.",
+	run:         runTrigger,
+}
+
+func runTrigger(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	dataFlag := fs.String("data", "", "the event data as JSON, or @ followed by the path of a JSON file")
+
+	positional, err := parseInterspersed(fs, args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("expected exactly one event")
+	}
+
+	data, err := parseData(*dataFlag)
+
+	if err != nil {
+		return err
+	}
+
+	d, err := loadDispatcher(*dir)
+
+	if err != nil {
+		return err
+	}
+
+	handles, triggerErr := d.TriggerWithRuns(positional[0], data)
+
+	if len(handles) == 0 && triggerErr == nil {
+		fmt.Printf("no workflows listen to event %s\n", positional[0])
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "WORKFLOW FILE\tJOB\tWORKFLOW ID\tRUN ID\tDEDUPLICATED")
+
+	for _, handle := range handles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", handle.WorkflowFile, orDash(handle.Job), handle.WorkflowID, handle.RunID, handle.Deduplicated)
+	}
+
+	w.Flush()
+
+	return triggerErr
+}
+
+// parseData parses event data passed as JSON, or as @ followed by the path of a JSON file.
+func parseData(dataFlag string) (any, error) {
+	if dataFlag == "" {
+		return map[string]any{}, nil
+	}
+
+	dataBytes := []byte(dataFlag)
+
+	if path, isFile := strings.CutPrefix(dataFlag, "@"); isFile {
+		fileBytes, err := os.ReadFile(path) // #nosec G304 -- the data file is passed by the user
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read data file: %w", err)
+		}
+
+		dataBytes = fileBytes
+	}
+
+	var data any
+
+	if err := json.Unmarshal(dataBytes, &data); err != nil {
+		return nil, fmt.Errorf("data is not valid JSON: %w", err)
+	}
+
+	return data, nil
+}
+
+// loadDispatcher returns a dispatcher for the workflow files in dir, connected to the Temporal server configured
+// by the environment. The client is loaded here rather than by the dispatcher, which panics if the client can't be
+// loaded, so that a missing or invalid configuration is printed as an error.
+func loadDispatcher(dir string) (dispatcher.DispatcherInterface, error) {
+	files, err := fileutils.ReadAllFilesInDir(dir)
+
+	if err != nil {
+		printProblems(err)
+		return nil, errProblemsFound
+	}
+
+	configLoader := &loader.ConfigLoader{}
+
+	hatchetClient, err := configLoader.LoadTemporalClient()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not load the Temporal client: %w", err)
+	}
+
+	return dispatcher.NewDispatcher(
+		dispatcher.WithHatchetClient(hatchetClient),
+		dispatcher.WithWorkflowFiles(files),
+	), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
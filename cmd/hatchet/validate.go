@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
+)
+
+var validateCmd = &command{
+	name:        "validate",
+	usage:       "validate [--dir ./.hatchet]",
+	description: "Validate every workflow file, using the same checks as the worker.",
+	run:         runValidate,
+}
+
+func runValidate(cmd *command, args []string) error {
+	fs, dir := newFlagSet(cmd)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := fileutils.ReadAllFilesInDir(*dir)
+
+	if err != nil {
+		printProblems(err)
+		return errProblemsFound
+	}
+
+	fmt.Printf("%d workflow files are valid\n", len(files))
+
+	return nil
+}
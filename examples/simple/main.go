@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"time"
@@ -38,8 +39,9 @@ func main() {
 	// directory, but this can be customized with the `dispatcher.WithTemporalClient` and `dispatcher.WithWorkflowFiles` options.
 	d := dispatcher.NewDispatcher()
 
-	// Trigger a new event. This will trigger any workflows which listen to the `user:create` event.
-	err = d.Trigger("user:create", map[string]any{
+	// Trigger a new event. This will trigger any workflows which listen to the `user:create` event, and returns a
+	// handle for each workflow run which was started.
+	runs, err := d.TriggerWithRuns("user:create", map[string]any{
 		"username": "echo-test",
 	})
 
@@ -48,7 +50,18 @@ func main() {
 	}
 
 	// wait for workflows to complete
-	time.Sleep(10 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, run := range runs {
+		results, err := run.Wait(ctx)
+
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("run %s completed with outputs: %v\n", run.WorkflowID, results[run.Job].Steps)
+	}
 }
 
 // EchoIntegration simply prints the message it receives and stores it in the `messages` field.
@@ -4,7 +4,6 @@ import (
 	"context"
 	_ "embed"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/hatchet-dev/hatchet-workflows/pkg/dispatcher"
@@ -16,19 +15,6 @@ import (
 //go:embed .hatchet/slack-channel.yaml
 var SlackChannelWorkflow []byte
 
-func init() {
-	// initialize the slack channel workflow with SLACK_USER_ID
-	slackUserId := os.Getenv("SLACK_USER_ID")
-
-	if slackUserId == "" {
-		panic("SLACK_USER_ID environment variable must be set")
-	}
-
-	slackFileWithReplacedEnv := strings.Replace(string(SlackChannelWorkflow), "$SLACK_USER_ID", slackUserId, 1)
-
-	SlackChannelWorkflow = []byte(slackFileWithReplacedEnv)
-}
-
 func main() {
 	// read the slack workflow
 	slackWorkflowFile, err := types.ParseYAML(context.Background(), SlackChannelWorkflow)
@@ -37,10 +23,16 @@ func main() {
 		panic(err)
 	}
 
-	// render the slack workflow using the environment variable SLACK_USER_ID
+	// the user added to the onboarding channel is read by the workflow file as `.secrets.SLACK_USER_ID`, which the
+	// worker resolves from HATCHET_SECRET_SLACK_USER_ID by default
+	slackUserId := os.Getenv("HATCHET_SECRET_SLACK_USER_ID")
 	slackToken := os.Getenv("SLACK_TOKEN")
 	slackTeamId := os.Getenv("SLACK_TEAM_ID")
 
+	if slackUserId == "" {
+		panic("HATCHET_SECRET_SLACK_USER_ID environment variable must be set")
+	}
+
 	if slackToken == "" {
 		panic("SLACK_TOKEN environment variable must be set")
 	}
@@ -0,0 +1,47 @@
+// Command schemagen writes the JSON Schema for workflow files. With -check, it exits with a non-zero status if the
+// schema file is out of date with the workflow file types instead of writing it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/schema"
+)
+
+func main() {
+	out := flag.String("out", "schemas/workflow-file."+schema.Version+".json", "the file to write the schema to")
+	check := flag.Bool("check", false, "check that the schema file is up to date instead of writing it")
+
+	flag.Parse()
+
+	schemaBytes, err := schema.Generate()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not generate schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		existing, err := os.ReadFile(*out)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not read schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !bytes.Equal(existing, schemaBytes) {
+			fmt.Fprintf(os.Stderr, "%s is out of date, run `go generate ./pkg/workflows/schema`\n", *out)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if err := os.WriteFile(*out, schemaBytes, 0o644); err != nil { // #nosec G306 -- the schema is not sensitive
+		fmt.Fprintf(os.Stderr, "could not write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,128 @@
+package datautils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The comparison functions replace the text/template builtins, so that numbers compare by value regardless of
+// their type. JSON numbers in the trigger input and step outputs are float64, so the builtins would fail to compare
+// `gt .total 1000` with "incompatible types for comparison".
+
+// eq returns whether arg1 is equal to any of arg2.
+func eq(arg1 interface{}, arg2 ...interface{}) (bool, error) {
+	if len(arg2) == 0 {
+		return false, fmt.Errorf("missing argument for comparison")
+	}
+
+	for _, arg := range arg2 {
+		equal, err := equalValues(arg1, arg)
+
+		if err != nil {
+			return false, err
+		}
+
+		if equal {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func ne(arg1, arg2 interface{}) (bool, error) {
+	equal, err := equalValues(arg1, arg2)
+	return !equal, err
+}
+
+func lt(arg1, arg2 interface{}) (bool, error) {
+	res, err := compareValues(arg1, arg2)
+	return res < 0, err
+}
+
+func le(arg1, arg2 interface{}) (bool, error) {
+	res, err := compareValues(arg1, arg2)
+	return res <= 0, err
+}
+
+func gt(arg1, arg2 interface{}) (bool, error) {
+	res, err := compareValues(arg1, arg2)
+	return res > 0, err
+}
+
+func ge(arg1, arg2 interface{}) (bool, error) {
+	res, err := compareValues(arg1, arg2)
+	return res >= 0, err
+}
+
+func equalValues(arg1, arg2 interface{}) (bool, error) {
+	if arg1 == nil || arg2 == nil {
+		return arg1 == nil && arg2 == nil, nil
+	}
+
+	num1, isNum1 := toNumber(arg1)
+	num2, isNum2 := toNumber(arg2)
+
+	if isNum1 && isNum2 {
+		return num1 == num2, nil
+	}
+
+	val1, val2 := reflect.ValueOf(arg1), reflect.ValueOf(arg2)
+
+	if val1.Kind() != val2.Kind() {
+		return false, fmt.Errorf("incompatible types for comparison: %T and %T", arg1, arg2)
+	}
+
+	if !val1.Comparable() || !val2.Comparable() {
+		return false, fmt.Errorf("non-comparable types for comparison: %T and %T", arg1, arg2)
+	}
+
+	switch val1.Kind() {
+	case reflect.String:
+		return val1.String() == val2.String(), nil
+	case reflect.Bool:
+		return val1.Bool() == val2.Bool(), nil
+	default:
+		return arg1 == arg2, nil
+	}
+}
+
+// compareValues returns -1, 0 or 1 if arg1 is less than, equal to or greater than arg2. Only numbers and strings
+// can be ordered.
+func compareValues(arg1, arg2 interface{}) (int, error) {
+	num1, isNum1 := toNumber(arg1)
+	num2, isNum2 := toNumber(arg2)
+
+	switch {
+	case isNum1 && isNum2:
+		switch {
+		case num1 < num2:
+			return -1, nil
+		case num1 > num2:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	case reflect.ValueOf(arg1).Kind() == reflect.String && reflect.ValueOf(arg2).Kind() == reflect.String:
+		return strings.Compare(reflect.ValueOf(arg1).String(), reflect.ValueOf(arg2).String()), nil
+	default:
+		return 0, fmt.Errorf("incompatible types for comparison: %T and %T", arg1, arg2)
+	}
+}
+
+// toNumber converts integers and floats to float64.
+func toNumber(val interface{}) (float64, bool) {
+	v := reflect.ValueOf(val)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
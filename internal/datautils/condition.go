@@ -0,0 +1,35 @@
+package datautils
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// EvaluateCondition evaluates a template pipeline like `eq .plan "enterprise"` against the data map, and returns
+// whether the result is truthy. The condition may optionally be wrapped in {{ }}. Values are truthy using the same
+// rules as a template `if` action: false, 0, nil, and empty strings, slices and maps are falsy. The functions returned
+// by [FuncMap] are available.
+func EvaluateCondition(data map[string]interface{}, condition string, opts ...RenderOptFunc) (bool, error) {
+	pipeline := trimDelimiters(condition)
+
+	if pipeline == "" {
+		return false, fmt.Errorf("condition is empty")
+	}
+
+	tmpl, err := template.New("if").Funcs(FuncMap(opts...)).Parse(fmt.Sprintf("{{ if %s }}true{{ end }}", pipeline))
+
+	if err != nil {
+		return false, fmt.Errorf("error parsing condition %q: %v", condition, err)
+	}
+
+	var tpl bytes.Buffer
+
+	err = tmpl.Execute(&tpl, data)
+
+	if err != nil {
+		return false, fmt.Errorf("error evaluating condition %q: %v", condition, err)
+	}
+
+	return tpl.String() == "true", nil
+}
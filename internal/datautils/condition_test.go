@@ -0,0 +1,73 @@
+package datautils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	data := map[string]interface{}{
+		"plan":    "enterprise",
+		"total":   float64(1500),
+		"count":   3,
+		"enabled": true,
+		"missing": nil,
+		"tags":    []interface{}{"a"},
+		"steps": map[string]interface{}{
+			"check": map[string]interface{}{
+				"outputs": map[string]interface{}{"skipped": false},
+			},
+		},
+	}
+
+	tests := []struct {
+		condition string
+		want      bool
+		wantErr   string
+	}{
+		{condition: `eq .plan "enterprise"`, want: true},
+		{condition: `{{ eq .plan "free" }}`, want: false},
+		{condition: `eq .plan "free" "enterprise"`, want: true},
+		{condition: `ne .plan "free"`, want: true},
+		{condition: `gt .total 1000`, want: true},
+		{condition: `gt .total 1000.0`, want: true},
+		{condition: `lt .total 1000`, want: false},
+		{condition: `ge .total 1500`, want: true},
+		{condition: `le .count 3.0`, want: true},
+		{condition: `eq .total 1500`, want: true},
+		{condition: `eq .count 3.0`, want: true},
+		{condition: `lt "a" "b"`, want: true},
+		{condition: `eq .enabled true`, want: true},
+		{condition: `eq .missing nil`, want: true},
+		{condition: `not .steps.check.outputs.skipped`, want: true},
+		{condition: `and .enabled (gt .total 1000)`, want: true},
+		{condition: `.tags`, want: true},
+		{condition: `eq .plan 1`, wantErr: "incompatible types for comparison"},
+		{condition: `gt .plan 1`, wantErr: "incompatible types for comparison"},
+		{condition: `eq .tags .tags`, wantErr: "non-comparable types"},
+		{condition: ``, wantErr: "condition is empty"},
+		{condition: `eq .plan (`, wantErr: "error parsing condition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			got, err := EvaluateCondition(data, tt.condition)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
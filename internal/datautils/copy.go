@@ -0,0 +1,34 @@
+package datautils
+
+// DeepCopyMap returns a copy of the map, recursively copying nested maps and lists so that the copy can be
+// modified without modifying the original.
+func DeepCopyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	res := make(map[string]interface{}, len(m))
+
+	for key, val := range m {
+		res[key] = deepCopyValue(val)
+	}
+
+	return res
+}
+
+func deepCopyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return DeepCopyMap(v)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+
+		for i, item := range v {
+			res[i] = deepCopyValue(item)
+		}
+
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,76 @@
+package datautils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// captureFuncName is the name of the template function used to capture the value of an expression.
+const captureFuncName = "__capture"
+
+// EvaluateExpression evaluates a template pipeline like `.steps.lookup.outputs.userIds` against the data map, and
+// returns the resulting value with its original type. The expression may optionally be wrapped in {{ }}. Missing
+// keys evaluate to nil. The functions returned by [FuncMap] are available.
+func EvaluateExpression(data map[string]interface{}, expression string, opts ...RenderOptFunc) (interface{}, error) {
+	return evaluateExpression(data, expression, "missingkey=default", opts...)
+}
+
+// evaluateExpression evaluates an expression, handling missing keys using the missingkey template option.
+func evaluateExpression(data map[string]interface{}, expression, missingKey string, opts ...RenderOptFunc) (interface{}, error) {
+	pipeline := trimDelimiters(expression)
+
+	if pipeline == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+
+	var res interface{}
+
+	tmpl, err := template.New("expression").Funcs(FuncMap(opts...)).Funcs(template.FuncMap{
+		captureFuncName: func(val interface{}) string {
+			res = val
+			return ""
+		},
+	}).Option(missingKey).Parse(fmt.Sprintf("{{ %s (%s) }}", captureFuncName, pipeline))
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing expression %q: %v", expression, err)
+	}
+
+	err = tmpl.Execute(io.Discard, data)
+
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating expression %q: %v", expression, err)
+	}
+
+	return res, nil
+}
+
+// EvaluateListExpression evaluates an expression which must result in a list. A nil result is an empty list.
+func EvaluateListExpression(data map[string]interface{}, expression string, opts ...RenderOptFunc) ([]interface{}, error) {
+	val, err := EvaluateExpression(data, expression, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := toList(val)
+
+	if err != nil {
+		return nil, fmt.Errorf("expression %q must result in a list, got %T", expression, val)
+	}
+
+	return res, nil
+}
+
+// trimDelimiters trims whitespace and a single pair of {{ }} delimiters around a pipeline.
+func trimDelimiters(pipeline string) string {
+	pipeline = strings.TrimSpace(pipeline)
+
+	if strings.HasPrefix(pipeline, "{{") && strings.HasSuffix(pipeline, "}}") && strings.Count(pipeline, "{{") == 1 {
+		pipeline = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(pipeline, "{{"), "}}"))
+	}
+
+	return pipeline
+}
@@ -0,0 +1,489 @@
+package datautils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// RenderOptFunc sets options for rendering templates and evaluating conditions and expressions.
+type RenderOptFunc func(*renderOpts)
+
+type renderOpts struct {
+	now *time.Time
+
+	deferredRoot string
+	deferred     func(tmplStr string) interface{}
+}
+
+func newRenderOpts(opts []RenderOptFunc) *renderOpts {
+	o := &renderOpts{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithNow sets the time returned by the `now` template function. Workflows should pass the deterministic time of
+// the workflow, so that templates render the same value when the workflow is replayed. If it is not set, `now`
+// returns an error.
+func WithNow(now time.Time) RenderOptFunc {
+	return func(opts *renderOpts) {
+		opts.now = &now
+	}
+}
+
+// WithDeferredRoot leaves fields which reference the top-level key root, like `.secrets`, to be rendered later.
+// [RenderTemplateFields] replaces these fields with the result of deferred, which is called with the unrendered
+// template.
+func WithDeferredRoot(root string, deferred func(tmplStr string) interface{}) RenderOptFunc {
+	return func(opts *renderOpts) {
+		opts.deferredRoot = root
+		opts.deferred = deferred
+	}
+}
+
+// FuncMap returns the functions available in templates, conditions and expressions, in addition to the
+// text/template builtins. Functions are deterministic: they never read the wall clock or generate random values,
+// so workflows render the same values when they are replayed.
+func FuncMap(opts ...RenderOptFunc) template.FuncMap {
+	o := newRenderOpts(opts)
+
+	res := make(template.FuncMap, len(templateFuncs)+1)
+
+	for name, fn := range templateFuncs {
+		res[name] = fn
+	}
+
+	res["now"] = func() (time.Time, error) {
+		if o.now == nil {
+			return time.Time{}, fmt.Errorf("now is only available when rendering within a workflow")
+		}
+
+		return *o.now, nil
+	}
+
+	return res
+}
+
+// templateFuncs are the template functions which do not depend on render options. Like the sprig library,
+// functions which are commonly piped take the piped value as their last argument, for example
+// `{{ .tags | join ", " }}`.
+var templateFuncs = template.FuncMap{
+	// comparisons, which compare numbers by value
+	"eq": eq,
+	"ne": ne,
+	"lt": lt,
+	"le": le,
+	"gt": gt,
+	"ge": ge,
+
+	// strings
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"title":      title,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"join":       join,
+	"split":      split,
+	"toString":   func(val interface{}) string { return fmt.Sprint(val) },
+
+	// defaults
+	"default":  defaultValue,
+	"coalesce": coalesce,
+	"empty":    func(val interface{}) bool { return !isTruthy(val) },
+
+	// encoding
+	"toJSON":    toJSON,
+	"fromJSON":  fromJSON,
+	"b64enc":    func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"b64dec":    b64dec,
+	"sha256sum": sha256sum,
+
+	// dates
+	"formatTime":  formatTime,
+	"parseTime":   time.Parse,
+	"addDuration": addDuration,
+
+	// lists
+	"list":   func(items ...interface{}) []interface{} { return items },
+	"first":  first,
+	"last":   last,
+	"append": appendList,
+	"uniq":   uniq,
+	"has":    has,
+
+	// maps
+	"dict":   dict,
+	"keys":   keys,
+	"values": values,
+	"hasKey": func(m map[string]interface{}, key string) bool { _, ok := m[key]; return ok },
+	"get":    get,
+	"pick":   pick,
+	"omit":   omit,
+}
+
+// title capitalizes the first letter of each word.
+func title(s string) string {
+	prev := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+
+		if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
+
+func join(sep string, list interface{}) (string, error) {
+	items, err := toList(list)
+
+	if err != nil {
+		return "", err
+	}
+
+	strs := make([]string, len(items))
+
+	for i, item := range items {
+		strs[i] = fmt.Sprint(item)
+	}
+
+	return strings.Join(strs, sep), nil
+}
+
+func split(sep, s string) []interface{} {
+	parts := strings.Split(s, sep)
+	res := make([]interface{}, len(parts))
+
+	for i, part := range parts {
+		res[i] = part
+	}
+
+	return res
+}
+
+// defaultValue returns val, or def if val is empty.
+func defaultValue(def, val interface{}) interface{} {
+	if !isTruthy(val) {
+		return def
+	}
+
+	return val
+}
+
+// coalesce returns the first value which is not empty, or nil if every value is empty.
+func coalesce(vals ...interface{}) interface{} {
+	for _, val := range vals {
+		if isTruthy(val) {
+			return val
+		}
+	}
+
+	return nil
+}
+
+// isTruthy uses the same rules as a template `if` action: false, 0, nil, and empty strings, lists and maps are
+// empty.
+func isTruthy(val interface{}) bool {
+	truth, _ := template.IsTrue(val)
+	return truth
+}
+
+func toJSON(val interface{}) (string, error) {
+	jsonBytes, err := json.Marshal(val)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(jsonBytes), nil
+}
+
+func fromJSON(s string) (interface{}, error) {
+	var res interface{}
+
+	if err := json.Unmarshal([]byte(s), &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func b64dec(s string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+func sha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatTime formats a time, or a string in RFC 3339 format, using a Go layout like "2006-01-02".
+func formatTime(layout string, t interface{}) (string, error) {
+	parsed, err := toTime(t)
+
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.Format(layout), nil
+}
+
+// addDuration adds a duration like "24h" or "-30m" to a time, or a string in RFC 3339 format.
+func addDuration(duration string, t interface{}) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	parsed, err := toTime(t)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parsed.Add(d), nil
+}
+
+func toTime(t interface{}) (time.Time, error) {
+	switch v := t.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, fmt.Errorf("time is nil")
+		}
+
+		return *v, nil
+	case string:
+		return time.Parse(time.RFC3339, v)
+	default:
+		return time.Time{}, fmt.Errorf("expected a time or a string in RFC 3339 format, got %T", t)
+	}
+}
+
+func first(list interface{}) (interface{}, error) {
+	items, err := toList(list)
+
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	return items[0], nil
+}
+
+func last(list interface{}) (interface{}, error) {
+	items, err := toList(list)
+
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+
+	return items[len(items)-1], nil
+}
+
+// appendList returns a new list with the item added to the end, without modifying the original list.
+func appendList(list interface{}, item interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]interface{}, 0, len(items)+1)
+	res = append(res, items...)
+
+	return append(res, item), nil
+}
+
+// uniq returns the list without duplicate items, keeping the first occurrence of each item.
+func uniq(list interface{}) ([]interface{}, error) {
+	items, err := toList(list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := []interface{}{}
+
+	for _, item := range items {
+		if !containsItem(res, item) {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// has returns whether the list contains the item.
+func has(item interface{}, list interface{}) (bool, error) {
+	items, err := toList(list)
+
+	if err != nil {
+		return false, err
+	}
+
+	return containsItem(items, item), nil
+}
+
+func containsItem(items []interface{}, item interface{}) bool {
+	for _, existing := range items {
+		if reflect.DeepEqual(existing, item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dict builds a map from pairs of keys and values, like `dict "name" .username "plan" "free"`.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires pairs of keys and values, got %d arguments", len(pairs))
+	}
+
+	res := make(map[string]interface{}, len(pairs)/2)
+
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+
+		res[key] = pairs[i+1]
+	}
+
+	return res, nil
+}
+
+// keys returns the keys of a map in sorted order.
+func keys(m map[string]interface{}) []interface{} {
+	res := make([]interface{}, 0, len(m))
+
+	for _, key := range sortedKeys(m) {
+		res = append(res, key)
+	}
+
+	return res
+}
+
+// values returns the values of a map, in the sorted order of their keys.
+func values(m map[string]interface{}) []interface{} {
+	res := make([]interface{}, 0, len(m))
+
+	for _, key := range sortedKeys(m) {
+		res = append(res, m[key])
+	}
+
+	return res
+}
+
+// get returns the value at a path of keys in nested maps, like `get .steps "lookup" "outputs" "count"`, or nil if
+// any key is missing. Unlike a field chain like `.steps.lookup.outputs.count`, it does not fail on missing keys,
+// so it can be combined with default.
+func get(m interface{}, path ...string) interface{} {
+	val := m
+
+	for _, key := range path {
+		nested, ok := val.(map[string]interface{})
+
+		if !ok {
+			return nil
+		}
+
+		val = nested[key]
+	}
+
+	return val
+}
+
+// pick returns a new map with only the given keys.
+func pick(m map[string]interface{}, keys ...string) map[string]interface{} {
+	res := map[string]interface{}{}
+
+	for _, key := range keys {
+		if val, ok := m[key]; ok {
+			res[key] = val
+		}
+	}
+
+	return res
+}
+
+// omit returns a new map without the given keys.
+func omit(m map[string]interface{}, keys ...string) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+
+	for key, val := range m {
+		res[key] = val
+	}
+
+	for _, key := range keys {
+		delete(res, key)
+	}
+
+	return res
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	res := make([]string, 0, len(m))
+
+	for key := range m {
+		res = append(res, key)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// toList converts a list of any type to a []interface{}. A nil value is an empty list.
+func toList(val interface{}) ([]interface{}, error) {
+	if val == nil {
+		return []interface{}{}, nil
+	}
+
+	if list, ok := val.([]interface{}); ok {
+		return list, nil
+	}
+
+	rv := reflect.ValueOf(val)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", val)
+	}
+
+	res := make([]interface{}, rv.Len())
+
+	for i := range res {
+		res[i] = rv.Index(i).Interface()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,99 @@
+package datautils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	data := map[string]interface{}{
+		"name":    "ada lovelace",
+		"empty":   "",
+		"zero":    0,
+		"tags":    []interface{}{"a", "b", "a"},
+		"numbers": []interface{}{1, 2.5},
+		"user":    map[string]interface{}{"id": "1", "email": "ada@example.com"},
+		"date":    "2024-03-01T10:30:00Z",
+	}
+
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr string
+	}{
+		{tmpl: `{{ .tags | join ", " }}`, want: "a, b, a"},
+		{tmpl: `{{ join "-" .numbers }}`, want: "1-2.5"},
+		{tmpl: `{{ join "," .name }}`, wantErr: "expected a list"},
+		{tmpl: `{{ split "," "a,b,c" | len }}`, want: "3"},
+		{tmpl: `{{ index (split " " .name) 1 }}`, want: "lovelace"},
+		{tmpl: `{{ .empty | default "anonymous" }}`, want: "anonymous"},
+		{tmpl: `{{ .zero | default 10 }}`, want: "10"},
+		{tmpl: `{{ .name | default "anonymous" }}`, want: "ada lovelace"},
+		{tmpl: `{{ coalesce .empty .zero "fallback" .name }}`, want: "fallback"},
+		{tmpl: `{{ coalesce .empty .zero }}`, want: "<no value>"},
+		{tmpl: `{{ .user | toJSON }}`, want: `{"email":"ada@example.com","id":"1"}`},
+		{tmpl: `{{ .tags | toJSON }}`, want: `["a","b","a"]`},
+		{tmpl: `{{ (fromJSON "{\"a\": 1}").a }}`, want: "1"},
+		{tmpl: `{{ "hello" | b64enc }}`, want: "aGVsbG8="},
+		{tmpl: `{{ "aGVsbG8=" | b64dec }}`, want: "hello"},
+		{tmpl: `{{ "hello" | sha256sum }}`, want: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{tmpl: `{{ .date | formatTime "2006-01-02" }}`, want: "2024-03-01"},
+		{tmpl: `{{ .date | addDuration "36h" | formatTime "Jan 2 15:04" }}`, want: "Mar 2 22:30"},
+		{tmpl: `{{ parseTime "2006-01-02" "2024-03-01" | formatTime "Monday" }}`, want: "Friday"},
+		{tmpl: `{{ .name | formatTime "2006" }}`, wantErr: "formatTime"},
+		{tmpl: `{{ .name | title }}`, want: "Ada Lovelace"},
+		{tmpl: `{{ .tags | uniq | join "" }}`, want: "ab"},
+		{tmpl: `{{ pick .user "id" | toJSON }}`, want: `{"id":"1"}`},
+		{tmpl: `{{ get . "user" "email" }}`, want: "ada@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := RenderTemplate(data, "test", tt.tmpl)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNow(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	// rendering twice with the same time gives the same result, as it would when a workflow is replayed
+	for i := 0; i < 2; i++ {
+		got, err := RenderTemplate(nil, "test", `{{ now | formatTime "2006-01-02T15:04" }}`, WithNow(now))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got != "2024-03-01T10:30" {
+			t.Errorf("got %q, want the injected time", got)
+		}
+	}
+
+	if _, err := RenderTemplate(nil, "test", `{{ now }}`); err == nil || !strings.Contains(err.Error(), "only available") {
+		t.Errorf("expected now to fail without an injected time, got %v", err)
+	}
+
+	ok, err := EvaluateCondition(nil, `gt (now | formatTime "2006") "2023"`, WithNow(now))
+
+	if err != nil || !ok {
+		t.Errorf("expected conditions to use the injected time, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,95 @@
+package datautils
+
+import (
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateReferences returns the field chains referenced in a template, like ["steps", "a", "outputs"] for
+// `{{ .steps.a.outputs.message }}`. It returns an error if the template can't be parsed or calls a function which
+// is not a text/template builtin or one of the functions returned by [FuncMap].
+func TemplateReferences(tmplStr string) ([][]string, error) {
+	tmpl, err := template.New("references").Funcs(FuncMap()).Parse(tmplStr)
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %q: %v", tmplStr, err)
+	}
+
+	res := [][]string{}
+
+	var walk func(node parse.Node)
+
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			res = append(res, n.Ident)
+		}
+	}
+
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+
+	return res, nil
+}
+
+// ExpressionReferences returns the field chains referenced in a template pipeline, like the conditions evaluated
+// by [EvaluateCondition] or expressions evaluated by [EvaluateExpression].
+func ExpressionReferences(expression string) ([][]string, error) {
+	return TemplateReferences(fmt.Sprintf("{{ %s }}", trimDelimiters(expression)))
+}
+
+// ReferencesRoot returns whether a template references a field of the top-level key root, like `.secrets.TOKEN`
+// for the root "secrets". Templates which can't be parsed reference nothing.
+func ReferencesRoot(tmplStr, root string) bool {
+	refs, err := TemplateReferences(tmplStr)
+
+	if err != nil {
+		return false
+	}
+
+	for _, ref := range refs {
+		if ref[0] == root {
+			return true
+		}
+	}
+
+	return false
+}
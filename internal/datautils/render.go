@@ -4,40 +4,148 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"text/template"
 )
 
-// RenderTemplateFields recursively processes the input map, rendering any string fields using the data map.
-func RenderTemplateFields(data map[string]interface{}, input map[string]interface{}) error {
-	for key, val := range input {
-		switch v := val.(type) {
-		case string:
-			tmpl, err := template.New(key).Parse(v)
+// typedExpressionRegex matches values which are exactly one `${{ expr }}` expression.
+var typedExpressionRegex = regexp.MustCompile(`^\s*\$\{\{(.*)\}\}\s*$`)
 
-			if err != nil {
-				return fmt.Errorf("error creating template for key %s: %v", key, err)
-			}
+// RenderTemplate renders a single template string using the data map. Missing keys are an error rather than
+// rendering as "<no value>". The functions returned by [FuncMap] are available. `${{ expr }}` expressions which are
+// part of a longer string are interpolated like `{{ expr }}`, so `Hello ${{ .name }}!` renders as "Hello bob!"
+// rather than "Hello $bob!". A literal dollar sign is written as `$${{ expr }}`.
+func RenderTemplate(data map[string]interface{}, name, tmplStr string, opts ...RenderOptFunc) (string, error) {
+	tmplStr = strings.ReplaceAll(tmplStr, "${{", "{{")
 
-			var tpl bytes.Buffer
-			err = tmpl.Execute(&tpl, data)
+	tmpl, err := template.New(name).Funcs(FuncMap(opts...)).Option("missingkey=error").Parse(tmplStr)
 
-			if err != nil {
-				return fmt.Errorf("error executing template for key %s: %v", key, err)
-			}
-			input[key] = tpl.String()
-		case map[string]interface{}:
-			// if we hit a nested map[string]interface{}, render those recursively
-			err := RenderTemplateFields(data, v)
-			if err != nil {
-				return err
-			}
-		default:
-			if reflect.TypeOf(v).Kind() == reflect.Map {
-				// If it's a map but not map[string]interface{}, return an error
-				return fmt.Errorf("encountered a map that is not map[string]interface{}: %s", key)
-			}
+	if err != nil {
+		return "", fmt.Errorf("error creating template %s: %v", name, err)
+	}
+
+	var tpl bytes.Buffer
+
+	err = tmpl.Execute(&tpl, data)
+
+	if err != nil {
+		return "", fmt.Errorf("error executing template %s: %v", name, err)
+	}
+
+	return tpl.String(), nil
+}
+
+// RenderValue renders a single template string using the data map. If the string is exactly one `${{ expr }}`
+// expression, the result of the expression is returned with its original type, so `${{ .steps.a.outputs.count }}`
+// can return a number, bool, list or map. Otherwise the string is rendered with [RenderTemplate] and the result is
+// a string. Missing keys are an error in both cases.
+func RenderValue(data map[string]interface{}, name, tmplStr string, opts ...RenderOptFunc) (interface{}, error) {
+	expression, ok := typedExpression(tmplStr)
+
+	if !ok {
+		return RenderTemplate(data, name, tmplStr, opts...)
+	}
+
+	res, err := evaluateExpression(data, expression, "missingkey=error", opts...)
+
+	if err != nil {
+		return nil, fmt.Errorf("error executing template %s: %w", name, err)
+	}
+
+	// lists and maps are copied, so that modifying the rendered value does not modify the data
+	return deepCopyValue(res), nil
+}
+
+// typedExpression returns the expression of a `${{ expr }}` value, and whether the value is a typed expression.
+func typedExpression(val string) (string, bool) {
+	matches := typedExpressionRegex.FindStringSubmatch(val)
+
+	if matches == nil {
+		return "", false
+	}
+
+	expression := strings.TrimSpace(matches[1])
+
+	// values like `${{ .a }} and {{ .b }}` contain more than one action, and are rendered as strings
+	if expression == "" || strings.Contains(expression, "{{") || strings.Contains(expression, "}}") {
+		return "", false
+	}
+
+	return expression, true
+}
+
+// RenderTemplateFields recursively processes the input map, rendering any string fields using the data map,
+// including strings in lists. Fields are rendered using [RenderValue], so missing keys are an error and
+// `${{ expr }}` fields keep the type of the expression's result. Fields can be left for rendering later using
+// [WithDeferredRoot].
+func RenderTemplateFields(data map[string]interface{}, input map[string]interface{}, opts ...RenderOptFunc) error {
+	return renderMapFields(data, "", input, opts)
+}
+
+func renderMapFields(data map[string]interface{}, path string, input map[string]interface{}, opts []RenderOptFunc) error {
+	// render keys in a stable order, so that the same error is returned each time
+	keys := make([]string, 0, len(input))
+
+	for key := range input {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := key
+
+		if path != "" {
+			fieldPath = path + "." + key
 		}
+
+		rendered, err := renderField(data, fieldPath, input[key], opts)
+
+		if err != nil {
+			return err
+		}
+
+		input[key] = rendered
 	}
 
 	return nil
 }
+
+func renderField(data map[string]interface{}, path string, val interface{}, opts []RenderOptFunc) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		if o := newRenderOpts(opts); o.deferredRoot != "" && ReferencesRoot(v, o.deferredRoot) {
+			return o.deferred(v), nil
+		}
+
+		return RenderValue(data, path, v, opts...)
+	case map[string]interface{}:
+		// if we hit a nested map[string]interface{}, render those recursively
+		if err := renderMapFields(data, path, v, opts); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			rendered, err := renderField(data, fmt.Sprintf("%s[%d]", path, i), item, opts)
+
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = rendered
+		}
+
+		return v, nil
+	default:
+		if v != nil && reflect.TypeOf(v).Kind() == reflect.Map {
+			// If it's a map but not map[string]interface{}, return an error
+			return nil, fmt.Errorf("encountered a map that is not map[string]interface{}: %s", path)
+		}
+
+		return v, nil
+	}
+}
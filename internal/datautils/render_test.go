@@ -0,0 +1,55 @@
+package datautils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderValue(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "bob",
+		"price": 42,
+		"tags":  []interface{}{"a", "b"},
+	}
+
+	tests := []struct {
+		tmpl    string
+		want    interface{}
+		wantErr string
+	}{
+		{tmpl: `Hello {{ .name }}!`, want: "Hello bob!"},
+		{tmpl: `Hello ${{ .name }}!`, want: "Hello bob!"},
+		{tmpl: `${{ .name }} and {{ .name }}`, want: "bob and bob"},
+		{tmpl: `${{ .name }}-${{ .price }}`, want: "bob-42"},
+		{tmpl: `Price: $${{ .price }}`, want: "Price: $42"},
+		{tmpl: `${{ .price }}`, want: 42},
+		{tmpl: ` ${{ .tags }} `, want: []interface{}{"a", "b"}},
+		{tmpl: `{{ .price }}`, want: "42"},
+		{tmpl: `$5`, want: "$5"},
+		{tmpl: `Hello ${{ .missing }}!`, wantErr: "map has no entry for key"},
+		{tmpl: `${{ .missing }}`, wantErr: "map has no entry for key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := RenderValue(data, "value", tt.tmpl)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
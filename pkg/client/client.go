@@ -222,6 +222,15 @@ func (c *Client) GetDefaultQueueName() string {
 	return c.opts.DefaultQueueName
 }
 
+// GetNamespace returns the Temporal namespace of the client.
+func (c *Client) GetNamespace() string {
+	if c.opts.Namespace == "" {
+		return client.DefaultNamespace
+	}
+
+	return c.opts.Namespace
+}
+
 func (c *Client) newQueueClient(taskQueueName string) (client.Client, error) {
 	err := c.eventualClientFromOpts(c.opts, taskQueueName, 1)
 	if err != nil {
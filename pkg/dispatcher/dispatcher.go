@@ -2,22 +2,36 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/google/uuid"
+	enums "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/sdk/client"
 
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/hatchet-dev/hatchet-workflows/internal/config/loader"
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
 	hatchetclient "github.com/hatchet-dev/hatchet-workflows/pkg/client"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
 )
 
 type Dispatcher struct {
-	c     *hatchetclient.Client
+	c     temporalClients
 	files []*types.WorkflowFile
 }
 
+// temporalClients returns the Temporal client of each task queue. It is implemented by [hatchetclient.Client].
+type temporalClients interface {
+	GetClient(queueName string) (client.Client, error)
+	GetDefaultQueueName() string
+	GetNamespace() string
+}
+
 type DispatchOpts struct {
 	clientLoader func() *hatchetclient.Client
 	filesLoader  func() []*types.WorkflowFile
@@ -57,8 +71,56 @@ func WithWorkflowFiles(files []*types.WorkflowFile) DispatchOptsFunc {
 	}
 }
 
+// WithStrictWorkflowFiles loads the workflow files from the .hatchet folder in the current directory like the default
+// loader, but panics if any file is invalid instead of logging and skipping it.
+func WithStrictWorkflowFiles() DispatchOptsFunc {
+	return func(opts *DispatchOpts) {
+		opts.filesLoader = fileutils.StrictLoader
+	}
+}
+
 type DispatcherInterface interface {
+	// Trigger starts all workflows which listen to the event.
 	Trigger(eventId string, data any) error
+
+	// TriggerWithRuns starts all workflows which listen to the event, and returns a handle for each workflow run
+	// which was started. If some runs fail to start, the handles of the started runs are returned along with the
+	// error.
+	TriggerWithRuns(eventId string, data any) ([]*RunHandle, error)
+
+	// ListRuns returns the runs of the workflow files which match the filter, most recent first.
+	ListRuns(ctx context.Context, filter RunFilter) ([]*RunInfo, error)
+
+	// DescribeRun returns the current state of a run, including its results once it has completed.
+	DescribeRun(ctx context.Context, workflowID, runID string) (*RunDescription, error)
+
+	// CancelRun requests cancellation of a run.
+	CancelRun(ctx context.Context, workflowID, runID string) error
+
+	// TerminateRun stops a run immediately.
+	TerminateRun(ctx context.Context, workflowID, runID, reason string) error
+
+	// InitSchedules creates or updates a Temporal schedule for each schedule of the workflow files.
+	InitSchedules() error
+
+	// ReconcileSchedules creates or updates a Temporal schedule for each schedule of the workflow files, and
+	// deletes or pauses the schedules created by Hatchet which are no longer declared.
+	ReconcileSchedules(ctx context.Context, opts ...ReconcileOptFunc) (*ScheduleDiff, error)
+
+	// ListSchedules returns the Temporal schedules managed by Hatchet, with their next run times.
+	ListSchedules(ctx context.Context) ([]*ScheduleInfo, error)
+
+	// PauseSchedule pauses a schedule, with a note like the reason it was paused.
+	PauseSchedule(ctx context.Context, scheduleId, note string) error
+
+	// ResumeSchedule resumes a paused schedule, with a note.
+	ResumeSchedule(ctx context.Context, scheduleId, note string) error
+
+	// TriggerSchedule starts a run of a schedule immediately.
+	TriggerSchedule(ctx context.Context, scheduleId string, overlap types.OverlapPolicy) error
+
+	// BackfillSchedule starts the runs of a schedule which were due in a time range.
+	BackfillSchedule(ctx context.Context, scheduleId string, start, end time.Time, overlap types.OverlapPolicy) error
 }
 
 func NewDispatcher(
@@ -78,164 +140,554 @@ func NewDispatcher(
 	return d
 }
 
+// InitSchedules creates or updates a Temporal schedule for each schedule of the workflow files. Schedules which
+// are no longer declared are kept; use [Dispatcher.ReconcileSchedules] to remove them.
 func (d *Dispatcher) InitSchedules() error {
+	_, err := d.reconcileSchedules(context.Background(), &reconcileOpts{
+		keepOrphans: true,
+	})
+
+	return err
+}
+
+// listDeclaredSchedules returns the Temporal schedules declared by the workflow files. Along with the error, it
+// returns the names of the files whose schedules could not be read, so that their schedules are not treated as
+// orphans.
+func (d *Dispatcher) listDeclaredSchedules() ([]*declaredSchedule, map[string]bool, error) {
 	var allErrs error
 
+	res := []*declaredSchedule{}
+	invalidFiles := map[string]bool{}
+
 	for _, file := range d.files {
-		if file.On.Cron.Schedule != "" {
-			err := d.dispatchAllScheduledJobs(file.On.Cron.Schedule, file.Jobs, nil)
+		schedules := file.On.ListSchedules()
+
+		if len(schedules) == 0 {
+			continue
+		}
+
+		_, err := types.ParseWorkflowTreeFromFile(*file)
+
+		if err != nil {
+			allErrs = multierror.Append(allErrs, fmt.Errorf("invalid workflow file %s: %w", file.Name, err))
+			invalidFiles[file.Name] = true
+			continue
+		}
+
+		if err := file.ValidateTimeouts(); err != nil {
+			allErrs = multierror.Append(allErrs, fmt.Errorf("invalid workflow file %s: %w", file.Name, err))
+			invalidFiles[file.Name] = true
+			continue
+		}
+
+		for _, schedule := range schedules {
+			scheduleCp := schedule
+
+			declared, err := d.getDeclaredSchedules(file, &scheduleCp)
 
 			if err != nil {
 				allErrs = multierror.Append(allErrs, err)
+				invalidFiles[file.Name] = true
 			}
 
-			allErrs = multierror.Append(allErrs, err)
+			res = append(res, declared...)
+		}
+	}
+
+	return res, invalidFiles, allErrs
+}
+
+// getDeclaredSchedules returns the Temporal schedules for a schedule of the workflow file. Scheduled runs start
+// with the static input of the schedule, with the defaults of the inputs of the file applied.
+func (d *Dispatcher) getDeclaredSchedules(file *types.WorkflowFile, schedule *types.WorkflowOnCron) ([]*declaredSchedule, error) {
+	parsed, err := schedule.Parse()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: schedule %s: %w", file.Name, schedule.Schedule, err)
+	}
+
+	data, err := file.ApplyInputs(parsed.Input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if file.UsesWorkflowRun() {
+		declared, err := d.getScheduledWorkflowRun(parsed, file, data)
+
+		if err != nil {
+			return nil, err
 		}
+
+		return []*declaredSchedule{declared}, nil
 	}
 
-	return allErrs
+	return d.getAllScheduledJobs(parsed, file, data)
 }
 
 func (d *Dispatcher) Trigger(eventId string, data any) error {
+	_, err := d.TriggerWithRuns(eventId, data)
+
+	return err
+}
+
+func (d *Dispatcher) TriggerWithRuns(eventId string, data any) ([]*RunHandle, error) {
 	// find all the workflows triggered from this event id
 	var allErrs error
 
+	handles := []*RunHandle{}
+
 	for _, file := range d.files {
 		fileCp := file
 
-		for _, event := range fileCp.On.Events {
-			if event == eventId {
-				err := d.dispatchAllJobs(fileCp.Jobs, data)
+		triggered, err := isTriggered(fileCp, eventId, data)
 
-				if err != nil {
-					allErrs = multierror.Append(allErrs, err)
-				}
+		if err != nil {
+			allErrs = multierror.Append(allErrs, err)
+			continue
+		}
+
+		if !triggered {
+			continue
+		}
+
+		fileHandles, err := d.dispatchFile(fileCp, eventId, data)
+
+		handles = append(handles, fileHandles...)
+
+		if err != nil {
+			allErrs = multierror.Append(allErrs, err)
+		}
+	}
+
+	return handles, allErrs
+}
+
+// isTriggered returns true if any event of the workflow file matches the event name and its filter passes. The
+// file is triggered at most once, even if several of its events match.
+func isTriggered(file *types.WorkflowFile, eventId string, data any) (bool, error) {
+	var dataMap map[string]any
+
+	for i, event := range file.On.Events {
+		if !event.Matches(eventId) {
+			continue
+		}
+
+		if event.If == "" {
+			return true, nil
+		}
+
+		// the data is only converted once a filter needs it
+		if dataMap == nil {
+			var err error
+
+			dataMap, err = datautils.ToJSONMap(data)
+
+			if err != nil {
+				return false, fmt.Errorf("workflow file %s: event data must be an object: %w", file.Name, err)
 			}
+
+			dataMap = datautils.MergeMaps(dataMap, map[string]any{
+				"env": datautils.DeepCopyMap(file.Env),
+			})
+		}
+
+		shouldRun, err := datautils.EvaluateCondition(datautils.DeepCopyMap(dataMap), event.If)
+
+		if err != nil {
+			return false, fmt.Errorf("workflow file %s: on.events[%d].if: %w", file.Name, i, err)
+		}
+
+		if shouldRun {
+			return true, nil
 		}
 	}
 
-	return allErrs
+	return false, nil
 }
 
-func (d *Dispatcher) dispatchAllJobs(jobs map[string]types.WorkflowJob, data any) error {
+// dispatchFile dispatches all jobs in a workflow file. If any job needs another job or the file has outputs, the
+// file is dispatched as a single workflow run which starts each job once its dependencies succeed. Otherwise, each
+// job is dispatched independently.
+func (d *Dispatcher) dispatchFile(file *types.WorkflowFile, event string, data any) ([]*RunHandle, error) {
+	_, err := types.ParseWorkflowTreeFromFile(*file)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %w", file.Name, err)
+	}
+
+	if err := file.ValidateTimeouts(); err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %w", file.Name, err)
+	}
+
+	// the input is validated before any workflow starts, so that invalid input does not fail the run
+	data, err = file.ApplyInputs(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runKey, err := getRunKey(file, data)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %w", file.Name, err)
+	}
+
+	reusePolicy, err := getIDReusePolicy(file.On)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %w", file.Name, err)
+	}
+
+	if file.UsesWorkflowRun() {
+		handle, err := d.dispatchWorkflowRun(file, event, data, runKey, reusePolicy)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*RunHandle{handle}, nil
+	}
+
+	return d.dispatchAllJobs(file, event, data, runKey, reusePolicy)
+}
+
+func (d *Dispatcher) dispatchWorkflowRun(file *types.WorkflowFile, event string, data any, runKey string, reusePolicy enums.WorkflowIdReusePolicy) (*RunHandle, error) {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	startOpts := client.StartWorkflowOptions{
+		ID:                    types.WorkflowRunID(file.Name, runKey),
+		TaskQueue:             d.c.GetDefaultQueueName(),
+		WorkflowIDReusePolicy: reusePolicy,
+		Memo:                  types.RunMemo(file.Name, "", event),
+	}
+
+	run, deduplicated, err := startWorkflow(tc, startOpts, file.Name, data, runKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	handle := newRunHandle(file, "", run)
+	handle.Deduplicated = deduplicated
+
+	return handle, nil
+}
+
+func (d *Dispatcher) dispatchAllJobs(file *types.WorkflowFile, event string, data any, runKey string, reusePolicy enums.WorkflowIdReusePolicy) ([]*RunHandle, error) {
 	var allErrs error
 
-	for jobName, job := range jobs {
+	handles := []*RunHandle{}
+
+	for jobName, job := range file.Jobs {
 		jobCp := job
-		err := d.dispatchJob(data, jobName, jobCp)
+		run, deduplicated, err := d.dispatchJob(data, file, jobName, jobCp, event, runKey, reusePolicy)
 
 		if err != nil {
 			allErrs = multierror.Append(allErrs, err)
+			continue
 		}
+
+		handle := newRunHandle(file, jobName, run)
+		handle.Deduplicated = deduplicated
+
+		handles = append(handles, handle)
 	}
 
-	return allErrs
+	return handles, allErrs
 }
 
-func (d *Dispatcher) dispatchJob(data any, jobName string, job types.WorkflowJob) error {
+func (d *Dispatcher) dispatchJob(data any, file *types.WorkflowFile, jobName string, job types.WorkflowJob, event, runKey string, reusePolicy enums.WorkflowIdReusePolicy) (client.WorkflowRun, bool, error) {
+	startOpts, err := getJobStartOptions(data, file, jobName, job, event, runKey, reusePolicy, d.c.GetDefaultQueueName())
+
+	if err != nil {
+		return nil, false, err
+	}
+
 	tc, err := d.c.GetClient(job.Queue)
 
 	if err != nil {
-		return err
+		return nil, false, err
+	}
+
+	return startWorkflow(tc, startOpts, types.JobWorkflowType(file.Name, jobName), data)
+}
+
+// getJobStartOptions returns the options of a job run. The job timeout bounds the whole run, including retries of
+// its steps, and jobs without a queue run on the default queue.
+func getJobStartOptions(data any, file *types.WorkflowFile, jobName string, job types.WorkflowJob, event, runKey string, reusePolicy enums.WorkflowIdReusePolicy, defaultQueue string) (client.StartWorkflowOptions, error) {
+	timeout, err := job.GetTimeout()
+
+	if err != nil {
+		return client.StartWorkflowOptions{}, fmt.Errorf("job %s: %w", jobName, err)
+	}
+
+	workflowID, err := file.RenderJobWorkflowID(data, jobName, runKey)
+
+	if err != nil {
+		return client.StartWorkflowOptions{}, err
 	}
 
 	taskQueue := job.Queue
 
 	if taskQueue == "" {
-		taskQueue = d.c.GetDefaultQueueName()
+		taskQueue = defaultQueue
 	}
 
-	startOpts := client.StartWorkflowOptions{
-		ID:        jobName,
-		TaskQueue: taskQueue,
-	}
+	return client.StartWorkflowOptions{
+		ID:                    workflowID,
+		TaskQueue:             taskQueue,
+		WorkflowRunTimeout:    timeout,
+		WorkflowIDReusePolicy: reusePolicy,
+		Memo:                  types.RunMemo(file.Name, jobName, event),
+	}, nil
+}
+
+// startWorkflow starts a workflow. If a workflow with the same ID already exists and the ID reuse policy does not
+// allow a new run, the existing run is returned with deduplicated=true.
+func startWorkflow(tc client.Client, startOpts client.StartWorkflowOptions, workflowName string, args ...any) (run client.WorkflowRun, deduplicated bool, err error) {
+	startOpts.WorkflowExecutionErrorWhenAlreadyStarted = true
 
-	_, err = tc.ExecuteWorkflow(
+	run, err = tc.ExecuteWorkflow(
 		context.Background(),
 		startOpts,
-		jobName,
-		data,
+		workflowName,
+		args...,
 	)
 
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+
+	if errors.As(err, &alreadyStarted) {
+		return tc.GetWorkflow(context.Background(), startOpts.ID, alreadyStarted.RunId), true, nil
+	}
+
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 
-	return nil
+	return run, false, nil
 }
 
-func (d *Dispatcher) dispatchAllScheduledJobs(inputSchedule string, jobs map[string]types.WorkflowJob, data any) error {
-	schedule, skipUpdateSchedule := parseScheduleInput(inputSchedule)
+// getRunKey returns the key which makes the workflow IDs of a trigger unique. This is the rendered idempotency key
+// of the file if set, or a new UUID otherwise.
+func getRunKey(file *types.WorkflowFile, data any) (string, error) {
+	if file.On.IdempotencyKey == "" {
+		return uuid.New().String(), nil
+	}
+
+	dataMap, err := datautils.ToJSONMap(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	dataMap = datautils.MergeMaps(dataMap, map[string]any{
+		"env": datautils.DeepCopyMap(file.Env),
+	})
+
+	key, err := datautils.RenderTemplate(dataMap, "idempotencyKey", file.On.IdempotencyKey)
+
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" {
+		return "", fmt.Errorf("idempotency key %q rendered an empty string", file.On.IdempotencyKey)
+	}
+
+	return key, nil
+}
+
+func getIDReusePolicy(on types.WorkflowOn) (enums.WorkflowIdReusePolicy, error) {
+	switch on.IDReusePolicy {
+	case "":
+		if on.IdempotencyKey != "" {
+			return enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE, nil
+		}
+
+		return enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, nil
+	case types.AllowDuplicate:
+		return enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, nil
+	case types.AllowDuplicateFailedOnly:
+		return enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY, nil
+	case types.RejectDuplicate:
+		return enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE, nil
+	case types.TerminateIfRunning:
+		return enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING, nil
+	default:
+		return enums.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED, fmt.Errorf("invalid idReusePolicy %q", on.IDReusePolicy)
+	}
+}
 
+func (d *Dispatcher) getAllScheduledJobs(schedule *types.CronSchedule, file *types.WorkflowFile, data any) ([]*declaredSchedule, error) {
 	var allErrs error
 
-	for jobName, job := range jobs {
+	res := []*declaredSchedule{}
+
+	for jobName, job := range file.Jobs {
 		jobCp := job
 
-		err := d.dispatchScheduledJob(schedule, skipUpdateSchedule, data, jobName, jobCp)
+		declared, err := d.getScheduledJob(schedule, data, file.Name, jobName, jobCp)
 
 		if err != nil {
 			allErrs = multierror.Append(allErrs, err)
+			continue
 		}
+
+		res = append(res, declared)
 	}
 
-	return allErrs
+	return res, allErrs
 }
 
-func (d *Dispatcher) dispatchScheduledJob(schedule string, skipUpdateSchedule bool, data any, jobName string, job types.WorkflowJob) error {
+func (d *Dispatcher) getScheduledJob(schedule *types.CronSchedule, data any, fileName, jobName string, job types.WorkflowJob) (*declaredSchedule, error) {
+	timeout, err := job.GetTimeout()
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %w", jobName, err)
+	}
+
 	tc, err := d.c.GetClient(job.Queue)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	taskQueue := job.Queue
+
+	if taskQueue == "" {
+		taskQueue = d.c.GetDefaultQueueName()
+	}
+
+	action := &client.ScheduleWorkflowAction{
+		TaskQueue:          taskQueue,
+		Workflow:           types.JobWorkflowType(fileName, jobName),
+		Args:               []interface{}{data},
+		WorkflowRunTimeout: timeout,
+		Memo:               types.RunMemo(fileName, jobName, ""),
+	}
+
+	declared := d.newDeclaredSchedule(tc, types.ScheduleID(fileName, jobName, schedule.Name), fileName, jobName, schedule, action)
+	declared.legacyId = getLegacyScheduleID(jobName, schedule.Name)
+
+	return declared, nil
+}
+
+func (d *Dispatcher) getScheduledWorkflowRun(schedule *types.CronSchedule, file *types.WorkflowFile, data any) (*declaredSchedule, error) {
+	tc, err := d.c.GetClient("")
+	if err != nil {
+		return nil, err
 	}
 
 	action := &client.ScheduleWorkflowAction{
-		TaskQueue: job.Queue,
-		Workflow:  jobName,
+		TaskQueue: d.c.GetDefaultQueueName(),
+		Workflow:  file.Name,
 		Args:      []interface{}{data},
+		Memo:      types.RunMemo(file.Name, "", ""),
 	}
 
-	// determine if schedule exists
-	scheduleHandle := tc.ScheduleClient().GetHandle(context.Background(), jobName)
+	return d.newDeclaredSchedule(tc, types.ScheduleID(file.Name, "", schedule.Name), file.Name, "", schedule, action), nil
+}
 
-	if scheduleHandle.GetID() == "" {
-		_, err = tc.ScheduleClient().Create(
-			context.Background(),
-			client.ScheduleOptions{
-				ID: jobName,
-				Spec: client.ScheduleSpec{
-					CronExpressions: []string{schedule},
-				},
-				Action: action,
-			},
-		)
-	} else {
-		err := scheduleHandle.Update(
-			context.Background(),
-			client.ScheduleUpdateOptions{
-				DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
-					if !skipUpdateSchedule {
-						input.Description.Schedule.Spec = &client.ScheduleSpec{
-							CronExpressions: []string{schedule},
-						}
-					}
-
-					input.Description.Schedule.Action = action
-
-					return &client.ScheduleUpdate{
-						Schedule: &input.Description.Schedule,
-					}, nil
-				},
+// upsertSchedule creates the Temporal schedule, or updates it if it exists. Created schedules are tagged with the
+// memo of their workflow file, so that they can be found when they are no longer declared.
+func upsertSchedule(ctx context.Context, declared *declaredSchedule, exists bool) error {
+	if !exists {
+		return createSchedule(ctx, declared, false, "")
+	}
+
+	scheduleHandle := declared.tc.ScheduleClient().GetHandle(ctx, declared.id)
+
+	return scheduleHandle.Update(
+		ctx,
+		client.ScheduleUpdateOptions{
+			DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+				spec := declared.spec
+
+				input.Description.Schedule.Spec = &spec
+
+				// the policy of a described schedule is always set
+				input.Description.Schedule.Policy.Overlap = declared.overlap
+				input.Description.Schedule.Action = declared.action
+
+				return &client.ScheduleUpdate{
+					Schedule: &input.Description.Schedule,
+				}, nil
 			},
-		)
-		if err != nil {
-			return err
-		}
-		return nil
+		},
+	)
+}
+
+func createSchedule(ctx context.Context, declared *declaredSchedule, paused bool, note string) error {
+	_, err := declared.tc.ScheduleClient().Create(
+		ctx,
+		client.ScheduleOptions{
+			ID:      declared.id,
+			Spec:    declared.spec,
+			Action:  declared.action,
+			Overlap: declared.overlap,
+			Memo:    types.RunMemo(declared.fileName, declared.jobName, ""),
+			Paused:  paused,
+			Note:    note,
+		},
+	)
+
+	return err
+}
+
+// recreateSchedule deletes the Temporal schedule and creates it again, keeping whether it is paused. The memo of a
+// schedule can only be set when it is created, so this tags schedules created before schedules were tagged.
+func recreateSchedule(ctx context.Context, declared *declaredSchedule, existing *client.ScheduleDescription) error {
+	if err := declared.tc.ScheduleClient().GetHandle(ctx, declared.id).Delete(ctx); err != nil {
+		return err
+	}
+
+	state := existing.Schedule.State
+
+	return createSchedule(ctx, declared, state.Paused, state.Note)
+}
+
+// describeExistingSchedule returns the description of the Temporal schedule, or nil if it does not exist.
+// Schedules are described rather than listed, as the list of schedules is eventually consistent.
+func describeExistingSchedule(ctx context.Context, tc client.Client, scheduleId string) (*client.ScheduleDescription, error) {
+	desc, err := tc.ScheduleClient().GetHandle(ctx, scheduleId).Describe(ctx)
+
+	var notFound *serviceerror.NotFound
+
+	if errors.As(err, &notFound) {
+		return nil, nil
 	}
 
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("error describing schedule %s: %w", scheduleId, err)
+	}
+
+	return desc, nil
+}
+
+// getOverlapPolicy maps an overlap policy to the Temporal schedule overlap policy. Schedules skip runs which are
+// due while the previous run is still running by default.
+func getOverlapPolicy(policy types.OverlapPolicy) enums.ScheduleOverlapPolicy {
+	switch policy {
+	case types.OverlapBufferOne:
+		return enums.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE
+	case types.OverlapBufferAll:
+		return enums.SCHEDULE_OVERLAP_POLICY_BUFFER_ALL
+	case types.OverlapCancelOther:
+		return enums.SCHEDULE_OVERLAP_POLICY_CANCEL_OTHER
+	case types.OverlapTerminateOther:
+		return enums.SCHEDULE_OVERLAP_POLICY_TERMINATE_OTHER
+	case types.OverlapAllowAll:
+		return enums.SCHEDULE_OVERLAP_POLICY_ALLOW_ALL
+	default:
+		return enums.SCHEDULE_OVERLAP_POLICY_SKIP
 	}
+}
 
-	return nil
+// getLegacyScheduleID returns the ID of a schedule of a job before the IDs of job schedules included the file
+// name, so that schedules created with the old ID can be removed.
+func getLegacyScheduleID(jobName, scheduleName string) string {
+	return types.ScheduleID(jobName, "", scheduleName)
 }
@@ -0,0 +1,165 @@
+package dispatcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	enums "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+func TestIsTriggered(t *testing.T) {
+	file := &types.WorkflowFile{
+		Name: "orders",
+		Env:  map[string]interface{}{"THRESHOLD": 100},
+		On: types.WorkflowOn{
+			Events: []types.WorkflowEvent{
+				{Name: "user:*"},
+				{Name: "order:created", If: "gt .total .env.THRESHOLD"},
+				{Name: "order:updated", If: `eq .status "paid"`},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		event   string
+		data    any
+		want    bool
+		wantErr string
+	}{
+		{
+			name:  "wildcard",
+			event: "user:create",
+			want:  true,
+		},
+		{
+			name:  "no matching event",
+			event: "order:deleted",
+			data:  map[string]any{"total": 1000},
+		},
+		{
+			name:  "filter passes",
+			event: "order:created",
+			data:  map[string]any{"total": 1000},
+			want:  true,
+		},
+		{
+			name:  "filter fails",
+			event: "order:created",
+			data:  map[string]any{"total": 10.5},
+		},
+		{
+			name:  "filter on struct data",
+			event: "order:updated",
+			data: struct {
+				Status string `json:"status"`
+			}{"paid"},
+			want: true,
+		},
+		{
+			name:    "filter on missing field",
+			event:   "order:created",
+			data:    map[string]any{},
+			wantErr: "workflow file orders: on.events[1].if",
+		},
+		{
+			name:    "data which is not an object",
+			event:   "order:created",
+			data:    []int{1},
+			wantErr: "event data must be an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isTriggered(file, tt.event, tt.data)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJobStartOptions(t *testing.T) {
+	file := &types.WorkflowFile{Name: "orders"}
+
+	tests := []struct {
+		name    string
+		job     types.WorkflowJob
+		want    client.StartWorkflowOptions
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			job:  types.WorkflowJob{},
+			want: client.StartWorkflowOptions{
+				ID:                    "orders/charge/key",
+				TaskQueue:             "default",
+				WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+				Memo:                  types.RunMemo("orders", "charge", "order:created"),
+			},
+		},
+		{
+			name: "timeout and queue",
+			job:  types.WorkflowJob{Timeout: "1h30m", Queue: "payments"},
+			want: client.StartWorkflowOptions{
+				ID:                    "orders/charge/key",
+				TaskQueue:             "payments",
+				WorkflowRunTimeout:    90 * time.Minute,
+				WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+				Memo:                  types.RunMemo("orders", "charge", "order:created"),
+			},
+		},
+		{
+			name:    "invalid timeout",
+			job:     types.WorkflowJob{Timeout: "soon"},
+			wantErr: `job charge: invalid timeout "soon"`,
+		},
+		{
+			name:    "negative timeout",
+			job:     types.WorkflowJob{Timeout: "-1m"},
+			wantErr: "must be greater than 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getJobStartOptions(map[string]any{}, file, "charge", tt.job, "order:created", "key",
+				enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, "default")
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got options %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,87 @@ The dispatcher package can be used to trigger workflows using event keys. For ex
 		}
 	}
 
+Event names in workflow files can contain wildcards like `user:*`, and events can have an `if` filter on the event
+data, so a workflow file may only be triggered by some of the events with a matching name. A workflow file is started
+at most once per call to Trigger.
+
+If a workflow file declares `inputs`, the data is validated against them and their defaults are applied before any
+workflow of the file starts. Invalid data returns a [types.InputError] for each invalid input, which can be found with
+errors.As.
+
+By default, the dispatcher loads the workflow files from the .hatchet directory, and logs and skips files which cannot be
+parsed or are invalid. Use the [WithStrictWorkflowFiles] option to panic on invalid files instead, or
+[WithWorkflowFiles] to pass in the files.
+
+# Waiting for Runs
+
+To get a handle for each workflow run started by an event, use [Dispatcher.TriggerWithRuns]. A [RunHandle] contains
+the Temporal workflow and run IDs, and can wait for the run to complete and return the outputs of each step:
+
+	runs, err := d.TriggerWithRuns("user:create", map[string]any{
+		"username": "testing12345",
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, run := range runs {
+		results, err := run.Wait(context.Background())
+
+		// ...
+	}
+
+If the workflow file declares outputs, [RunHandle.WaitResult] returns them along with the result of each job. The
+outputs of a file and its jobs are a stable contract for callers, which does not depend on the IDs of steps.
+
+# Managing Runs
+
+Every run started by the dispatcher records the workflow file, job and event which started it. [Dispatcher.ListRuns]
+finds runs by these fields, and [Dispatcher.DescribeRun], [Dispatcher.CancelRun] and [Dispatcher.TerminateRun] inspect
+and stop a single run:
+
+	runs, err := d.ListRuns(context.Background(), dispatcher.RunFilter{
+		Event: "user:create",
+		Limit: 10,
+	})
+
+# Scheduling Workflows
+
+Workflow files with a `cron` schedule or a list of `schedules` are run by Temporal schedules. [Dispatcher.InitSchedules]
+creates or updates a schedule for each of them, with its timezone, jitter, time range, static input and overlap policy:
+
+	if err := d.InitSchedules(); err != nil {
+		panic(err)
+	}
+
+[Dispatcher.InitSchedules] keeps the schedules which are no longer declared. [Dispatcher.ReconcileSchedules] also deletes
+the schedules created by Hatchet which were removed from the workflow files, or pauses them with [WithPauseOrphans],
+and returns the schedules it changed. [WithDryRun] returns the changes without making them:
+
+	diff, err := d.ReconcileSchedules(ctx, dispatcher.WithDryRun())
+
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(diff.Created, diff.Updated, diff.Deleted)
+
+Schedules created by Hatchet are tagged with their workflow file in their memo. Declared schedules which exist without
+the tag, like schedules created by older versions of Hatchet, are deleted and created again with the tag, and are listed
+in [ScheduleDiff.Recreated].
+
+Schedules managed by Hatchet can be listed with [Dispatcher.ListSchedules], which returns their next run times. They
+can be paused and resumed with a note, triggered immediately, or backfilled over a time range:
+
+	if err := d.PauseSchedule(ctx, "Post User Sign Up/print-user/nightly", "paused during incident"); err != nil {
+		panic(err)
+	}
+
+	if err := d.BackfillSchedule(ctx, "Post User Sign Up/print-user/nightly", start, end, types.OverlapBufferAll); err != nil {
+		panic(err)
+	}
+
 # Adding Workflow Files
 
 By default, the dispatcher will load workflow files from the .hatchet directory. You can override this using the [WithWorkflowFiles] option:
@@ -0,0 +1,76 @@
+package dispatcher
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/client"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// RunHandle is a handle to a Temporal workflow run started by the dispatcher.
+type RunHandle struct {
+	// WorkflowFile is the name of the workflow file which was triggered.
+	WorkflowFile string
+
+	// Job is the name of the job which was started. It is empty if the file has job dependencies, in which case
+	// the run starts every job in the file.
+	Job string
+
+	WorkflowID string
+	RunID      string
+
+	// Deduplicated is true if the run was not started because a run with the same idempotency key already
+	// exists. In this case, the handle refers to the existing run.
+	Deduplicated bool
+
+	run client.WorkflowRun
+}
+
+func newRunHandle(file *types.WorkflowFile, jobName string, run client.WorkflowRun) *RunHandle {
+	return &RunHandle{
+		WorkflowFile: file.Name,
+		Job:          jobName,
+		WorkflowID:   run.GetID(),
+		RunID:        run.GetRunID(),
+		run:          run,
+	}
+}
+
+// Wait blocks until the run completes, and returns the result of each job in the run keyed by job name. The
+// results contain the final outputs of each step. If the run failed, the error of the run is returned.
+func (h *RunHandle) Wait(ctx context.Context) (map[string]*types.JobResult, error) {
+	res, err := h.WaitResult(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+}
+
+// WaitResult blocks until the run completes, and returns the result of the run, including the outputs of the
+// workflow file. If the run failed, the error of the run is returned.
+func (h *RunHandle) WaitResult(ctx context.Context) (*types.RunResult, error) {
+	if h.Job == "" {
+		var result types.RunResult
+
+		if err := h.run.Get(ctx, &result); err != nil {
+			return nil, err
+		}
+
+		return &result, nil
+	}
+
+	var result types.JobResult
+
+	if err := h.run.Get(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return &types.RunResult{
+		Jobs: map[string]*types.JobResult{
+			h.Job: &result,
+		},
+	}, nil
+}
@@ -0,0 +1,334 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enums "go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// RunFilter filters the runs returned by [Dispatcher.ListRuns]. Empty fields match every run.
+type RunFilter struct {
+	WorkflowFile string
+	Job          string
+	Event        string
+
+	// Limit is the maximum number of runs to return. 0 returns every run.
+	Limit int
+}
+
+// RunInfo describes a Temporal workflow run started by Hatchet.
+type RunInfo struct {
+	WorkflowFile string
+
+	// Job is empty for runs of a workflow file with job dependencies.
+	Job string
+
+	// Event is empty for scheduled runs.
+	Event string
+
+	WorkflowID string
+	RunID      string
+
+	// Status is the status of the Temporal workflow, like Running, Completed or Failed.
+	Status string
+
+	StartTime time.Time
+
+	// CloseTime is zero while the run is running.
+	CloseTime time.Time
+}
+
+// RunDescription is the current state of a run, returned by [Dispatcher.DescribeRun].
+type RunDescription struct {
+	*RunInfo
+
+	// PendingSteps are the actions of the steps which are currently running.
+	PendingSteps []PendingStep
+
+	// PendingJobs are the workflow IDs of the jobs which are currently running, for runs of a workflow file with
+	// job dependencies.
+	PendingJobs []string
+
+	// Results contains the result of each job keyed by job name, once the run has completed successfully.
+	Results map[string]*types.JobResult
+
+	// Outputs contains the outputs of the workflow file, once a run of a workflow file with outputs has completed
+	// successfully.
+	Outputs map[string]interface{}
+
+	// Error is the error of the run, if it did not complete successfully.
+	Error string
+}
+
+// PendingStep is a step which is currently running.
+type PendingStep struct {
+	ActionID    string
+	Attempt     int32
+	LastFailure string
+}
+
+// ListRuns returns the runs of the dispatcher's workflow files which match the filter, most recent first: running
+// runs come first, followed by closed runs from the most recently closed, which is the order of Temporal's
+// visibility store. The workflow file and job are filtered by the visibility query, while the event is only
+// stored in the memo of each run, so it is filtered as the runs are listed. Listing stops once the limit is reached.
+func (d *Dispatcher) ListRuns(ctx context.Context, filter RunFilter) ([]*RunInfo, error) {
+	res := []*RunInfo{}
+
+	workflowTypes := d.listWorkflowTypes(filter)
+
+	if len(workflowTypes) == 0 {
+		return res, nil
+	}
+
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	req := &workflowservice.ListWorkflowExecutionsRequest{
+		Query: runsQuery(workflowTypes),
+	}
+
+	if filter.Limit > 0 {
+		req.PageSize = int32(filter.Limit)
+	}
+
+	for {
+		resp, err := tc.ListWorkflow(ctx, req)
+
+		if err != nil {
+			return nil, fmt.Errorf("error listing runs: %w", err)
+		}
+
+		for _, execution := range resp.Executions {
+			run := toRunInfo(execution)
+
+			if run == nil || !filter.matches(run) {
+				continue
+			}
+
+			res = append(res, run)
+
+			if filter.Limit > 0 && len(res) == filter.Limit {
+				return res, nil
+			}
+		}
+
+		if len(resp.NextPageToken) == 0 {
+			return res, nil
+		}
+
+		req.NextPageToken = resp.NextPageToken
+	}
+}
+
+// runsQuery returns the visibility query which lists the runs of the given Temporal workflows.
+func runsQuery(workflowTypes []string) string {
+	quoted := make([]string, 0, len(workflowTypes))
+
+	for _, workflowType := range workflowTypes {
+		quoted = append(quoted, fmt.Sprintf("'%s'", strings.ReplaceAll(workflowType, "'", "\\'")))
+	}
+
+	if len(quoted) == 1 {
+		return "WorkflowType = " + quoted[0]
+	}
+
+	return fmt.Sprintf("WorkflowType IN (%s)", strings.Join(quoted, ", "))
+}
+
+// listWorkflowTypes returns the names of the Temporal workflows which can match the filter. Jobs are registered
+// under their file and job name, and workflow files with job dependencies or outputs under the file name.
+func (d *Dispatcher) listWorkflowTypes(filter RunFilter) []string {
+	seen := map[string]bool{}
+	res := []string{}
+
+	add := func(workflowType string) {
+		if !seen[workflowType] {
+			seen[workflowType] = true
+			res = append(res, workflowType)
+		}
+	}
+
+	for _, file := range d.files {
+		if filter.WorkflowFile != "" && file.Name != filter.WorkflowFile {
+			continue
+		}
+
+		if file.UsesWorkflowRun() && filter.Job == "" {
+			add(file.Name)
+		}
+
+		for _, jobName := range file.ListJobNames() {
+			if filter.Job == "" || jobName == filter.Job {
+				add(types.JobWorkflowType(file.Name, jobName))
+			}
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+func (f RunFilter) matches(run *RunInfo) bool {
+	return (f.WorkflowFile == "" || run.WorkflowFile == f.WorkflowFile) &&
+		(f.Job == "" || run.Job == f.Job) &&
+		(f.Event == "" || run.Event == f.Event)
+}
+
+// DescribeRun returns the current state of a run. If runID is empty, the latest run of the workflow is described.
+func (d *Dispatcher) DescribeRun(ctx context.Context, workflowID, runID string) (*RunDescription, error) {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := tc.DescribeWorkflowExecution(ctx, workflowID, runID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error describing run %s: %w", workflowID, err)
+	}
+
+	info := toRunInfo(resp.WorkflowExecutionInfo)
+
+	if info == nil {
+		return nil, fmt.Errorf("workflow %s was not started by hatchet", workflowID)
+	}
+
+	res := &RunDescription{
+		RunInfo:      info,
+		PendingSteps: []PendingStep{},
+		PendingJobs:  []string{},
+	}
+
+	for _, activity := range resp.PendingActivities {
+		step := PendingStep{
+			ActionID: activity.GetActivityType().GetName(),
+			Attempt:  activity.Attempt,
+		}
+
+		if activity.LastFailure != nil {
+			step.LastFailure = activity.LastFailure.Message
+		}
+
+		res.PendingSteps = append(res.PendingSteps, step)
+	}
+
+	for _, child := range resp.PendingChildren {
+		res.PendingJobs = append(res.PendingJobs, child.WorkflowId)
+	}
+
+	if resp.WorkflowExecutionInfo.Status == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return res, nil
+	}
+
+	handle := &RunHandle{
+		WorkflowFile: info.WorkflowFile,
+		Job:          info.Job,
+		WorkflowID:   info.WorkflowID,
+		RunID:        info.RunID,
+		run:          tc.GetWorkflow(ctx, info.WorkflowID, info.RunID),
+	}
+
+	result, err := handle.WaitResult(ctx)
+
+	var execErr *temporal.WorkflowExecutionError
+
+	// the execution error repeats the workflow and run IDs, so only its cause is kept
+	if errors.As(err, &execErr) && errors.Unwrap(execErr) != nil {
+		err = errors.Unwrap(execErr)
+	}
+
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Results = result.Jobs
+		res.Outputs = result.Outputs
+	}
+
+	return res, nil
+}
+
+// CancelRun requests cancellation of a run. Failure handlers of the running jobs still run. If runID is empty,
+// the latest run of the workflow is cancelled.
+func (d *Dispatcher) CancelRun(ctx context.Context, workflowID, runID string) error {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return err
+	}
+
+	return tc.CancelWorkflow(ctx, workflowID, runID)
+}
+
+// TerminateRun stops a run immediately, without running failure handlers. If runID is empty, the latest run of
+// the workflow is terminated.
+func (d *Dispatcher) TerminateRun(ctx context.Context, workflowID, runID, reason string) error {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return err
+	}
+
+	return tc.TerminateWorkflow(ctx, workflowID, runID, reason)
+}
+
+// toRunInfo converts a Temporal workflow execution to a run, or returns nil if the workflow was not started
+// by Hatchet.
+func toRunInfo(execution *workflowpb.WorkflowExecutionInfo) *RunInfo {
+	fileName := memoString(execution.Memo, types.MemoWorkflowFile)
+
+	if fileName == "" {
+		return nil
+	}
+
+	res := &RunInfo{
+		WorkflowFile: fileName,
+		Job:          memoString(execution.Memo, types.MemoJob),
+		Event:        memoString(execution.Memo, types.MemoEvent),
+		WorkflowID:   execution.GetExecution().GetWorkflowId(),
+		RunID:        execution.GetExecution().GetRunId(),
+		Status:       execution.Status.String(),
+	}
+
+	if execution.StartTime != nil {
+		res.StartTime = *execution.StartTime
+	}
+
+	if execution.CloseTime != nil {
+		res.CloseTime = *execution.CloseTime
+	}
+
+	return res
+}
+
+func memoString(memo *commonpb.Memo, key string) string {
+	payload, exists := memo.GetFields()[key]
+
+	if !exists {
+		return ""
+	}
+
+	var res string
+
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &res); err != nil {
+		return ""
+	}
+
+	return res
+}
@@ -0,0 +1,115 @@
+package dispatcher
+
+import (
+	"testing"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+func TestRunsQuery(t *testing.T) {
+	d := &Dispatcher{
+		files: []*types.WorkflowFile{
+			{
+				Name: "signup",
+				Jobs: map[string]types.WorkflowJob{
+					"greet":  {},
+					"notify": {},
+				},
+			},
+			{
+				Name: "orders",
+				Jobs: map[string]types.WorkflowJob{
+					"charge": {},
+					"ship":   {Needs: []string{"charge"}},
+				},
+			},
+			{
+				Name: "report's",
+				Jobs: map[string]types.WorkflowJob{
+					"o'clock": {},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter RunFilter
+		want   string
+	}{
+		{
+			name:   "every file",
+			filter: RunFilter{},
+			want:   `WorkflowType IN ('orders', 'orders/charge', 'orders/ship', 'report\'s/o\'clock', 'signup/greet', 'signup/notify')`,
+		},
+		{
+			name:   "file",
+			filter: RunFilter{WorkflowFile: "signup"},
+			want:   `WorkflowType IN ('signup/greet', 'signup/notify')`,
+		},
+		{
+			name:   "file with job dependencies",
+			filter: RunFilter{WorkflowFile: "orders"},
+			want:   `WorkflowType IN ('orders', 'orders/charge', 'orders/ship')`,
+		},
+		{
+			name:   "job",
+			filter: RunFilter{Job: "ship"},
+			want:   `WorkflowType = 'orders/ship'`,
+		},
+		{
+			name:   "job of another file",
+			filter: RunFilter{WorkflowFile: "signup", Job: "ship"},
+			want:   "",
+		},
+		{
+			name:   "quotes",
+			filter: RunFilter{WorkflowFile: "report's"},
+			want:   `WorkflowType = 'report\'s/o\'clock'`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			workflowTypes := d.listWorkflowTypes(tt.filter)
+
+			got := ""
+
+			if len(workflowTypes) > 0 {
+				got = runsQuery(workflowTypes)
+			}
+
+			if got != tt.want {
+				t.Errorf("got query %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFilterMatches(t *testing.T) {
+	run := &RunInfo{WorkflowFile: "signup", Job: "greet", Event: "user:create"}
+
+	tests := []struct {
+		name   string
+		filter RunFilter
+		want   bool
+	}{
+		{name: "empty", filter: RunFilter{}, want: true},
+		{name: "all fields", filter: RunFilter{WorkflowFile: "signup", Job: "greet", Event: "user:create"}, want: true},
+		{name: "other file", filter: RunFilter{WorkflowFile: "orders"}, want: false},
+		{name: "other job", filter: RunFilter{Job: "notify"}, want: false},
+		{name: "other event", filter: RunFilter{Event: "user:delete"}, want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(run); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,40 +1,67 @@
 package dispatcher
 
 import (
-	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
-	"math/big"
+	"math/rand"
+	"strings"
 
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
 )
 
-func parseScheduleInput(input string) (parsedSchedule string, skipUpdateSchedule bool) {
-	switch input {
+// parseScheduleInput returns the cron expression of the schedule. Random schedules pick their times from a random
+// source seeded by the seed, so that every replica of the dispatcher picks the same times on every start.
+func parseScheduleInput(schedule *types.CronSchedule, seed string) string {
+	r := rand.New(rand.NewSource(getSeed(seed)))
+
+	switch schedule.Schedule {
 	case string(types.Random15Min):
-		return get15MinRandomSchedule(), true
+		return get15MinRandomSchedule(r)
 	case string(types.RandomHourly):
-		return getHourlyRandomSchedule(), true
+		return getHourlyRandomSchedule(r)
 	case string(types.RandomDaily):
-		return getDailyRandomSchedule(), true
+		return getDailyRandomSchedule(r, schedule.Window)
+	case string(types.RandomWeekly):
+		return getWeeklyRandomSchedule(r, schedule.Window)
 	default:
-		return input, false
+		return schedule.Schedule
 	}
 }
 
-func getDailyRandomSchedule() string {
-	hour := getRandomInt(24)
+// getScheduleSeed returns the seed of the random times of a schedule, which is unique to the schedule within
+// the Temporal namespace.
+func getScheduleSeed(namespace, fileName, scheduleId string) string {
+	return strings.Join([]string{namespace, fileName, scheduleId}, "/")
+}
+
+func getSeed(seed string) int64 {
+	sum := sha256.Sum256([]byte(seed))
+
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+func getWeeklyRandomSchedule(r *rand.Rand, window *types.TimeWindow) string {
+	day := r.Intn(7)
+	hour, minute := getRandomTimeOfDay(r, window)
 
-	return fmt.Sprintf("0 %d * * *", hour)
+	return fmt.Sprintf("%d %d * * %d", minute, hour, day)
 }
 
-func getHourlyRandomSchedule() string {
-	minute := getRandomInt(60)
+func getDailyRandomSchedule(r *rand.Rand, window *types.TimeWindow) string {
+	hour, minute := getRandomTimeOfDay(r, window)
+
+	return fmt.Sprintf("%d %d * * *", minute, hour)
+}
+
+func getHourlyRandomSchedule(r *rand.Rand) string {
+	minute := r.Intn(60)
 
 	return fmt.Sprintf("%d * * * *", minute)
 }
 
-func get15MinRandomSchedule() string {
-	firstQuarter := getRandomInt(15)
+func get15MinRandomSchedule(r *rand.Rand) string {
+	firstQuarter := r.Intn(15)
 
 	return fmt.Sprintf(
 		"%d,%d,%d,%d * * * *",
@@ -45,13 +72,13 @@ func get15MinRandomSchedule() string {
 	)
 }
 
-// getRandomInt generates random int in the range [0, max)
-func getRandomInt(max int64) int64 {
-	nBig, err := rand.Int(rand.Reader, big.NewInt(max))
-
-	if err != nil {
-		panic(err)
+// getRandomTimeOfDay picks a time of day in the window, or in the whole day if the window is nil.
+func getRandomTimeOfDay(r *rand.Rand, window *types.TimeWindow) (hour, minute int) {
+	if window == nil {
+		window = &types.TimeWindow{}
 	}
 
-	return nBig.Int64()
+	minutes := (window.Start + r.Intn(window.Minutes())) % (24 * 60)
+
+	return minutes / 60, minutes % 60
 }
@@ -0,0 +1,203 @@
+package dispatcher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+func TestParseScheduleInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *types.CronSchedule
+		check    func(fields []int) error
+	}{
+		{
+			name:     "random_15_min",
+			schedule: &types.CronSchedule{Schedule: string(types.Random15Min)},
+			check: func(fields []int) error {
+				if len(fields) != 4 {
+					return fmt.Errorf("expected 4 minutes")
+				}
+
+				for i, minute := range fields {
+					if minute != fields[0]+15*i || fields[0] >= 15 {
+						return fmt.Errorf("expected minutes 15 minutes apart in the first quarter")
+					}
+				}
+
+				return nil
+			},
+		},
+		{
+			name:     "random_hourly",
+			schedule: &types.CronSchedule{Schedule: string(types.RandomHourly)},
+			check: func(fields []int) error {
+				return inRange(fields[0], 0, 59)
+			},
+		},
+		{
+			name:     "random_daily",
+			schedule: &types.CronSchedule{Schedule: string(types.RandomDaily)},
+			check: func(fields []int) error {
+				return inWindow(fields[1], fields[0], types.TimeWindow{})
+			},
+		},
+		{
+			name: "random_daily in a window",
+			schedule: &types.CronSchedule{
+				Schedule: string(types.RandomDaily),
+				Window:   &types.TimeWindow{Start: 9 * 60, End: 9*60 + 30},
+			},
+			check: func(fields []int) error {
+				return inWindow(fields[1], fields[0], types.TimeWindow{Start: 9 * 60, End: 9*60 + 30})
+			},
+		},
+		{
+			name: "random_weekly in a window crossing midnight",
+			schedule: &types.CronSchedule{
+				Schedule: string(types.RandomWeekly),
+				Window:   &types.TimeWindow{Start: 23 * 60, End: 60},
+			},
+			check: func(fields []int) error {
+				if err := inRange(fields[2], 0, 6); err != nil {
+					return err
+				}
+
+				return inWindow(fields[1], fields[0], types.TimeWindow{Start: 23 * 60, End: 60})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distinct := map[string]bool{}
+
+			for i := 0; i < 50; i++ {
+				seed := getScheduleSeed("default", "reports", fmt.Sprintf("reports/send/%d", i))
+				got := parseScheduleInput(tt.schedule, seed)
+
+				if again := parseScheduleInput(tt.schedule, seed); again != got {
+					t.Fatalf("got %q and %q for the same seed", got, again)
+				}
+
+				fields, err := parseRandomCron(got)
+
+				if err == nil {
+					err = tt.check(fields)
+				}
+
+				if err != nil {
+					t.Fatalf("invalid schedule %q: %v", got, err)
+				}
+
+				distinct[got] = true
+			}
+
+			// different schedules are spread out
+			if len(distinct) < 2 {
+				t.Errorf("got the same schedule for every seed")
+			}
+		})
+	}
+
+	cron := &types.CronSchedule{Schedule: "0 9 * * 1-5"}
+
+	if got := parseScheduleInput(cron, "seed"); got != "0 9 * * 1-5" {
+		t.Errorf("got %q, want cron expressions unchanged", got)
+	}
+}
+
+// TestParseScheduleInputIsStable pins the times picked for a seed, as changing them moves every random schedule
+// when the dispatcher is upgraded.
+func TestParseScheduleInputIsStable(t *testing.T) {
+	seed := getScheduleSeed("default", "reports", "reports/send")
+
+	tests := []struct {
+		schedule *types.CronSchedule
+		want     string
+	}{
+		{&types.CronSchedule{Schedule: string(types.Random15Min)}, "10,25,40,55 * * * *"},
+		{&types.CronSchedule{Schedule: string(types.RandomHourly)}, "10 * * * *"},
+		{&types.CronSchedule{Schedule: string(types.RandomDaily)}, "10 23 * * *"},
+		{&types.CronSchedule{Schedule: string(types.RandomWeekly), Window: &types.TimeWindow{Start: 9 * 60, End: 17 * 60}}, "24 15 * * 3"},
+	}
+
+	for _, tt := range tests {
+		if got := parseScheduleInput(tt.schedule, seed); got != tt.want {
+			t.Errorf("got %q for %s, want %q", got, tt.schedule.Schedule, tt.want)
+		}
+	}
+}
+
+func TestGetScheduleSeed(t *testing.T) {
+	seeds := map[int64]string{}
+
+	for _, parts := range [][3]string{
+		{"default", "reports", "reports/send"},
+		{"staging", "reports", "reports/send"},
+		{"default", "other", "reports/send"},
+		{"default", "reports", "reports/archive"},
+	} {
+		seed := getSeed(getScheduleSeed(parts[0], parts[1], parts[2]))
+
+		if existing, exists := seeds[seed]; exists {
+			t.Errorf("got the same seed for %v and %s", parts, existing)
+		}
+
+		seeds[seed] = strings.Join(parts[:], ", ")
+	}
+}
+
+// parseRandomCron returns the numeric fields of a generated cron expression. Lists like the minutes of
+// random_15_min are returned as separate fields, and `*` fields are skipped.
+func parseRandomCron(cron string) ([]int, error) {
+	res := []int{}
+
+	for _, field := range strings.Fields(cron) {
+		if field == "*" {
+			continue
+		}
+
+		for _, item := range strings.Split(field, ",") {
+			num, err := strconv.Atoi(item)
+
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, num)
+		}
+	}
+
+	return res, nil
+}
+
+func inRange(val, min, max int) error {
+	if val < min || val > max {
+		return fmt.Errorf("%d is not between %d and %d", val, min, max)
+	}
+
+	return nil
+}
+
+func inWindow(hour, minute int, window types.TimeWindow) error {
+	if err := inRange(hour, 0, 23); err != nil {
+		return err
+	}
+
+	if err := inRange(minute, 0, 59); err != nil {
+		return err
+	}
+
+	offset := (hour*60 + minute - window.Start + 24*60) % (24 * 60)
+
+	if offset >= window.Minutes() {
+		return fmt.Errorf("%02d:%02d is not in the window", hour, minute)
+	}
+
+	return nil
+}
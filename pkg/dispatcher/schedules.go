@@ -0,0 +1,569 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	enums "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// declaredSchedule is a Temporal schedule declared by a schedule of a workflow file.
+type declaredSchedule struct {
+	id       string
+	fileName string
+
+	// jobName is empty for schedules which run every job in the file.
+	jobName string
+
+	// legacyId is the ID of the schedule before the IDs of job schedules included the file name, or empty.
+	legacyId string
+
+	tc      client.Client
+	spec    client.ScheduleSpec
+	overlap enums.ScheduleOverlapPolicy
+	action  *client.ScheduleWorkflowAction
+}
+
+// newDeclaredSchedule returns the Temporal schedule for a schedule of a workflow file. The times of random
+// schedules are derived from the namespace, the workflow file and the schedule ID.
+func (d *Dispatcher) newDeclaredSchedule(tc client.Client, scheduleId, fileName, jobName string, schedule *types.CronSchedule, action *client.ScheduleWorkflowAction) *declaredSchedule {
+	seed := getScheduleSeed(d.c.GetNamespace(), fileName, scheduleId)
+
+	return &declaredSchedule{
+		id:       scheduleId,
+		fileName: fileName,
+		jobName:  jobName,
+		tc:       tc,
+		spec: client.ScheduleSpec{
+			CronExpressions: []string{parseScheduleInput(schedule, seed)},
+			TimeZoneName:    schedule.Timezone,
+			Jitter:          schedule.Jitter,
+			StartAt:         schedule.Start,
+			EndAt:           schedule.End,
+		},
+		overlap: getOverlapPolicy(schedule.Overlap),
+		action:  action,
+	}
+}
+
+// ScheduleDiff lists the IDs of the Temporal schedules changed by [Dispatcher.ReconcileSchedules]. In a dry run,
+// it lists the schedules which would be changed.
+type ScheduleDiff struct {
+	Created []string
+	Updated []string
+
+	// Recreated lists the schedules which were created before schedules were tagged with the memo of their
+	// workflow file. They are deleted and created again with the memo, so that they are removed once they are no
+	// longer declared.
+	Recreated []string
+
+	Deleted []string
+	Paused  []string
+}
+
+type reconcileOpts struct {
+	dryRun       bool
+	pauseOrphans bool
+
+	// keepOrphans leaves schedules which are no longer declared unchanged.
+	keepOrphans bool
+}
+
+type ReconcileOptFunc func(*reconcileOpts)
+
+// WithDryRun returns the changes which the reconciliation would make, without changing any schedule.
+func WithDryRun() ReconcileOptFunc {
+	return func(opts *reconcileOpts) {
+		opts.dryRun = true
+	}
+}
+
+// WithPauseOrphans pauses the schedules which are no longer declared instead of deleting them, so that they can
+// be inspected or resumed.
+func WithPauseOrphans() ReconcileOptFunc {
+	return func(opts *reconcileOpts) {
+		opts.pauseOrphans = true
+	}
+}
+
+// orphanPauseNote is the note set on schedules paused because they are no longer declared.
+const orphanPauseNote = "paused by Hatchet: the schedule is no longer declared in a workflow file"
+
+// ReconcileSchedules creates or updates a Temporal schedule for each schedule of the workflow files, and deletes
+// the schedules created by Hatchet which are no longer declared, like the schedules of removed jobs or files.
+// Schedules created by Hatchet are found by their memo. Schedules of workflow files which are invalid are left
+// unchanged.
+//
+// The dispatcher must be loaded with every workflow file which uses the Temporal namespace, as the schedules of
+// files it does not know about are treated as orphans.
+func (d *Dispatcher) ReconcileSchedules(ctx context.Context, opts ...ReconcileOptFunc) (*ScheduleDiff, error) {
+	reconcileOpts := &reconcileOpts{}
+
+	for _, opt := range opts {
+		opt(reconcileOpts)
+	}
+
+	return d.reconcileSchedules(ctx, reconcileOpts)
+}
+
+func (d *Dispatcher) reconcileSchedules(ctx context.Context, opts *reconcileOpts) (*ScheduleDiff, error) {
+	var allErrs error
+
+	declared, invalidFiles, err := d.listDeclaredSchedules()
+
+	if err != nil {
+		allErrs = multierror.Append(allErrs, err)
+	}
+
+	diff := &ScheduleDiff{
+		Created:   []string{},
+		Updated:   []string{},
+		Recreated: []string{},
+		Deleted:   []string{},
+		Paused:    []string{},
+	}
+
+	declaredIDs := map[string]bool{}
+	deletedIDs := map[string]bool{}
+
+	for _, schedule := range declared {
+		declaredIDs[schedule.id] = true
+	}
+
+	for _, schedule := range declared {
+		if schedule.legacyId != "" && !declaredIDs[schedule.legacyId] {
+			deleted, err := d.removeLegacySchedule(ctx, schedule, opts.dryRun)
+
+			if err != nil {
+				allErrs = multierror.Append(allErrs, err)
+			} else if deleted {
+				deletedIDs[schedule.legacyId] = true
+				diff.Deleted = append(diff.Deleted, schedule.legacyId)
+			}
+		}
+
+		existing, err := describeExistingSchedule(ctx, schedule.tc, schedule.id)
+
+		if err != nil {
+			allErrs = multierror.Append(allErrs, err)
+			continue
+		}
+
+		// schedules created before schedules were tagged have no memo, and are recreated to tag them
+		if existing != nil && memoString(existing.Memo, types.MemoWorkflowFile) == "" {
+			if !opts.dryRun {
+				if err := recreateSchedule(ctx, schedule, existing); err != nil {
+					allErrs = multierror.Append(allErrs, fmt.Errorf("error recreating schedule %s: %w", schedule.id, err))
+					continue
+				}
+			}
+
+			diff.Recreated = append(diff.Recreated, schedule.id)
+			continue
+		}
+
+		if !opts.dryRun {
+			if err := upsertSchedule(ctx, schedule, existing != nil); err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("error upserting schedule %s: %w", schedule.id, err))
+				continue
+			}
+		}
+
+		if existing != nil {
+			diff.Updated = append(diff.Updated, schedule.id)
+		} else {
+			diff.Created = append(diff.Created, schedule.id)
+		}
+	}
+
+	sort.Strings(diff.Created)
+	sort.Strings(diff.Updated)
+	sort.Strings(diff.Recreated)
+	sort.Strings(diff.Deleted)
+
+	if opts.keepOrphans {
+		return diff, allErrs
+	}
+
+	existing, err := d.listExistingSchedules(ctx)
+
+	if err != nil {
+		return diff, multierror.Append(allErrs, err)
+	}
+
+	for id, entry := range existing {
+		fileName := memoString(entry.Memo, types.MemoWorkflowFile)
+
+		// schedules not created by Hatchet, and schedules of invalid files, are left unchanged
+		if fileName == "" || invalidFiles[fileName] || declaredIDs[id] || deletedIDs[id] {
+			continue
+		}
+
+		if opts.pauseOrphans && entry.Paused {
+			continue
+		}
+
+		if !opts.dryRun {
+			if err := d.removeOrphan(ctx, id, opts.pauseOrphans); err != nil {
+				allErrs = multierror.Append(allErrs, err)
+				continue
+			}
+		}
+
+		if opts.pauseOrphans {
+			diff.Paused = append(diff.Paused, id)
+		} else {
+			diff.Deleted = append(diff.Deleted, id)
+		}
+	}
+
+	sort.Strings(diff.Deleted)
+	sort.Strings(diff.Paused)
+
+	return diff, allErrs
+}
+
+// removeLegacySchedule deletes the schedule with the legacy ID of the declared schedule, which was created before
+// the IDs of job schedules included the file name, so that the job does not run on both schedules. The schedule is
+// only deleted if its memo is of the same job or it has no memo, and it returns whether it was deleted.
+func (d *Dispatcher) removeLegacySchedule(ctx context.Context, schedule *declaredSchedule, dryRun bool) (bool, error) {
+	desc, err := describeExistingSchedule(ctx, schedule.tc, schedule.legacyId)
+
+	if err != nil || desc == nil {
+		return false, err
+	}
+
+	fileName := memoString(desc.Memo, types.MemoWorkflowFile)
+	jobName := memoString(desc.Memo, types.MemoJob)
+
+	if (fileName != "" && fileName != schedule.fileName) || (jobName != "" && jobName != schedule.jobName) {
+		return false, nil
+	}
+
+	if !dryRun {
+		if err := schedule.tc.ScheduleClient().GetHandle(ctx, schedule.legacyId).Delete(ctx); err != nil {
+			return false, fmt.Errorf("error deleting schedule %s: %w", schedule.legacyId, err)
+		}
+	}
+
+	return true, nil
+}
+
+// listExistingSchedules returns every Temporal schedule in the namespace, keyed by schedule ID.
+func (d *Dispatcher) listExistingSchedules(ctx context.Context) (map[string]*client.ScheduleListEntry, error) {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := tc.ScheduleClient().List(ctx, client.ScheduleListOptions{})
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing schedules: %w", err)
+	}
+
+	res := map[string]*client.ScheduleListEntry{}
+
+	for iter.HasNext() {
+		entry, err := iter.Next()
+
+		if err != nil {
+			return nil, fmt.Errorf("error listing schedules: %w", err)
+		}
+
+		res[entry.ID] = entry
+	}
+
+	return res, nil
+}
+
+func (d *Dispatcher) removeOrphan(ctx context.Context, scheduleId string, pause bool) error {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return err
+	}
+
+	handle := tc.ScheduleClient().GetHandle(ctx, scheduleId)
+
+	if pause {
+		if err := handle.Pause(ctx, client.SchedulePauseOptions{Note: orphanPauseNote}); err != nil {
+			return fmt.Errorf("error pausing schedule %s: %w", scheduleId, err)
+		}
+
+		return nil
+	}
+
+	if err := handle.Delete(ctx); err != nil {
+		return fmt.Errorf("error deleting schedule %s: %w", scheduleId, err)
+	}
+
+	return nil
+}
+
+// ScheduleInfo describes a Temporal schedule managed by Hatchet.
+type ScheduleInfo struct {
+	ID           string
+	WorkflowFile string
+
+	// Job is empty for schedules which run every job in a workflow file.
+	Job string
+
+	Paused bool
+
+	// Note is the note of the schedule, like the reason it was paused.
+	Note string
+
+	// Declared is false for schedules which are no longer declared in a workflow file, and are removed by
+	// [Dispatcher.ReconcileSchedules].
+	Declared bool
+
+	// NextRunTimes are the next times the schedule runs. It is empty while the schedule is paused.
+	NextRunTimes []time.Time
+}
+
+// ListSchedules returns the Temporal schedules managed by Hatchet, sorted by ID. These are the schedules created
+// by Hatchet, and the declared schedules of the workflow files.
+func (d *Dispatcher) ListSchedules(ctx context.Context) ([]*ScheduleInfo, error) {
+	declared := d.getDeclaredScheduleIDs()
+
+	existing, err := d.listExistingSchedules(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+
+	for id, entry := range existing {
+		if memoString(entry.Memo, types.MemoWorkflowFile) != "" || declared[id] != nil {
+			ids = append(ids, id)
+		}
+	}
+
+	// the list of schedules is eventually consistent, so declared schedules which were just created are
+	// described as well
+	for id := range declared {
+		if _, exists := existing[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+
+	res := []*ScheduleInfo{}
+
+	for _, id := range ids {
+		schedule, err := d.describeSchedule(ctx, id, declared)
+
+		var notFound *serviceerror.NotFound
+
+		// declared schedules which were never created, and schedules which were just deleted, are skipped
+		if errors.As(err, &notFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, schedule)
+	}
+
+	return res, nil
+}
+
+// PauseSchedule pauses a schedule managed by Hatchet. The note is shown on the schedule, like the reason it was
+// paused.
+func (d *Dispatcher) PauseSchedule(ctx context.Context, scheduleId, note string) error {
+	handle, err := d.getManagedSchedule(ctx, scheduleId)
+
+	if err != nil {
+		return err
+	}
+
+	if err := handle.Pause(ctx, client.SchedulePauseOptions{Note: note}); err != nil {
+		return fmt.Errorf("error pausing schedule %s: %w", scheduleId, err)
+	}
+
+	return nil
+}
+
+// ResumeSchedule resumes a paused schedule managed by Hatchet, and replaces its note.
+func (d *Dispatcher) ResumeSchedule(ctx context.Context, scheduleId, note string) error {
+	handle, err := d.getManagedSchedule(ctx, scheduleId)
+
+	if err != nil {
+		return err
+	}
+
+	if err := handle.Unpause(ctx, client.ScheduleUnpauseOptions{Note: note}); err != nil {
+		return fmt.Errorf("error resuming schedule %s: %w", scheduleId, err)
+	}
+
+	return nil
+}
+
+// TriggerSchedule starts a run of a schedule managed by Hatchet immediately, even if the schedule is paused. The
+// overlap policy overrides the policy of the schedule if set.
+func (d *Dispatcher) TriggerSchedule(ctx context.Context, scheduleId string, overlap types.OverlapPolicy) error {
+	overlapPolicy, err := getOverrideOverlapPolicy(overlap)
+
+	if err != nil {
+		return err
+	}
+
+	handle, err := d.getManagedSchedule(ctx, scheduleId)
+
+	if err != nil {
+		return err
+	}
+
+	if err := handle.Trigger(ctx, client.ScheduleTriggerOptions{Overlap: overlapPolicy}); err != nil {
+		return fmt.Errorf("error triggering schedule %s: %w", scheduleId, err)
+	}
+
+	return nil
+}
+
+// BackfillSchedule starts the runs of a schedule managed by Hatchet which were due between start and end, as if
+// the time range passed by now. The overlap policy overrides the policy of the schedule if set; as the runs are
+// all due at once, the skip policy only starts the first of them.
+func (d *Dispatcher) BackfillSchedule(ctx context.Context, scheduleId string, start, end time.Time, overlap types.OverlapPolicy) error {
+	if !end.After(start) {
+		return fmt.Errorf("the end of the backfill must be after its start")
+	}
+
+	overlapPolicy, err := getOverrideOverlapPolicy(overlap)
+
+	if err != nil {
+		return err
+	}
+
+	handle, err := d.getManagedSchedule(ctx, scheduleId)
+
+	if err != nil {
+		return err
+	}
+
+	err = handle.Backfill(ctx, client.ScheduleBackfillOptions{
+		Backfill: []client.ScheduleBackfill{
+			{
+				Start:   start,
+				End:     end,
+				Overlap: overlapPolicy,
+			},
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("error backfilling schedule %s: %w", scheduleId, err)
+	}
+
+	return nil
+}
+
+// getDeclaredScheduleIDs returns the declared schedules of the workflow files, keyed by schedule ID. Schedules of
+// invalid workflow files are omitted.
+func (d *Dispatcher) getDeclaredScheduleIDs() map[string]*declaredSchedule {
+	// errors are reported by InitSchedules and ReconcileSchedules
+	declared, _, _ := d.listDeclaredSchedules()
+
+	res := map[string]*declaredSchedule{}
+
+	for _, schedule := range declared {
+		res[schedule.id] = schedule
+	}
+
+	return res
+}
+
+// describeSchedule returns the current state of the schedule. Describing a schedule is consistent, unlike
+// listing schedules, so a schedule which was just paused is shown as paused.
+func (d *Dispatcher) describeSchedule(ctx context.Context, scheduleId string, declared map[string]*declaredSchedule) (*ScheduleInfo, error) {
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := tc.ScheduleClient().GetHandle(ctx, scheduleId).Describe(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("error describing schedule %s: %w", scheduleId, err)
+	}
+
+	res := &ScheduleInfo{
+		ID:           scheduleId,
+		WorkflowFile: memoString(desc.Memo, types.MemoWorkflowFile),
+		Job:          memoString(desc.Memo, types.MemoJob),
+		Paused:       desc.Schedule.State.Paused,
+		Note:         desc.Schedule.State.Note,
+		Declared:     declared[scheduleId] != nil,
+		NextRunTimes: []time.Time{},
+	}
+
+	// schedules created before schedules were tagged have no memo
+	if res.WorkflowFile == "" && res.Declared {
+		res.WorkflowFile = declared[scheduleId].fileName
+		res.Job = declared[scheduleId].jobName
+	}
+
+	if !res.Paused {
+		res.NextRunTimes = append(res.NextRunTimes, desc.Info.NextActionTimes...)
+	}
+
+	return res, nil
+}
+
+// getManagedSchedule returns a handle to the schedule, or an error if the schedule is not managed by Hatchet.
+func (d *Dispatcher) getManagedSchedule(ctx context.Context, scheduleId string) (client.ScheduleHandle, error) {
+	schedule, err := d.describeSchedule(ctx, scheduleId, d.getDeclaredScheduleIDs())
+
+	var notFound *serviceerror.NotFound
+
+	if errors.As(err, &notFound) {
+		return nil, fmt.Errorf("schedule %s does not exist", scheduleId)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.WorkflowFile == "" {
+		return nil, fmt.Errorf("schedule %s is not managed by hatchet", scheduleId)
+	}
+
+	tc, err := d.c.GetClient("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tc.ScheduleClient().GetHandle(ctx, scheduleId), nil
+}
+
+// getOverrideOverlapPolicy maps an overlap policy which overrides the policy of a schedule. An empty policy keeps
+// the policy of the schedule.
+func getOverrideOverlapPolicy(policy types.OverlapPolicy) (enums.ScheduleOverlapPolicy, error) {
+	if policy == "" {
+		return enums.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED, nil
+	}
+
+	for _, validPolicy := range types.OverlapPolicies {
+		if policy == validPolicy {
+			return getOverlapPolicy(policy), nil
+		}
+	}
+
+	return enums.SCHEDULE_OVERLAP_POLICY_UNSPECIFIED, fmt.Errorf("invalid overlap policy %q", policy)
+}
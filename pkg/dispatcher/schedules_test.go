@@ -0,0 +1,611 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// fakeSchedules is an in-memory Temporal schedule client, which records the calls which change schedules.
+type fakeSchedules struct {
+	schedules map[string]*fakeSchedule
+	calls     []string
+}
+
+type fakeSchedule struct {
+	memo   map[string]interface{}
+	paused bool
+	note   string
+}
+
+func newFakeSchedules(schedules map[string]*fakeSchedule) *fakeSchedules {
+	if schedules == nil {
+		schedules = map[string]*fakeSchedule{}
+	}
+
+	return &fakeSchedules{schedules: schedules, calls: []string{}}
+}
+
+func (f *fakeSchedules) Create(ctx context.Context, options client.ScheduleOptions) (client.ScheduleHandle, error) {
+	f.calls = append(f.calls, "create "+options.ID)
+
+	f.schedules[options.ID] = &fakeSchedule{
+		memo:   options.Memo,
+		paused: options.Paused,
+		note:   options.Note,
+	}
+
+	return f.GetHandle(ctx, options.ID), nil
+}
+
+func (f *fakeSchedules) List(ctx context.Context, options client.ScheduleListOptions) (client.ScheduleListIterator, error) {
+	ids := make([]string, 0, len(f.schedules))
+
+	for id := range f.schedules {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	entries := []*client.ScheduleListEntry{}
+
+	for _, id := range ids {
+		schedule := f.schedules[id]
+
+		entries = append(entries, &client.ScheduleListEntry{
+			ID:     id,
+			Memo:   toMemo(schedule.memo),
+			Paused: schedule.paused,
+			Note:   schedule.note,
+		})
+	}
+
+	return &fakeScheduleIterator{entries: entries}, nil
+}
+
+func (f *fakeSchedules) GetHandle(ctx context.Context, scheduleID string) client.ScheduleHandle {
+	return &fakeScheduleHandle{schedules: f, id: scheduleID}
+}
+
+type fakeScheduleIterator struct {
+	entries []*client.ScheduleListEntry
+}
+
+func (i *fakeScheduleIterator) HasNext() bool {
+	return len(i.entries) > 0
+}
+
+func (i *fakeScheduleIterator) Next() (*client.ScheduleListEntry, error) {
+	entry := i.entries[0]
+	i.entries = i.entries[1:]
+
+	return entry, nil
+}
+
+type fakeScheduleHandle struct {
+	schedules *fakeSchedules
+	id        string
+}
+
+func (h *fakeScheduleHandle) GetID() string {
+	return h.id
+}
+
+func (h *fakeScheduleHandle) get() (*fakeSchedule, error) {
+	schedule, exists := h.schedules.schedules[h.id]
+
+	if !exists {
+		return nil, serviceerror.NewNotFound("schedule not found")
+	}
+
+	return schedule, nil
+}
+
+// call records a call which changes the schedule, and returns the schedule.
+func (h *fakeScheduleHandle) call(name string) (*fakeSchedule, error) {
+	schedule, err := h.get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	h.schedules.calls = append(h.schedules.calls, name+" "+h.id)
+
+	return schedule, nil
+}
+
+func (h *fakeScheduleHandle) Delete(ctx context.Context) error {
+	if _, err := h.call("delete"); err != nil {
+		return err
+	}
+
+	delete(h.schedules.schedules, h.id)
+
+	return nil
+}
+
+func (h *fakeScheduleHandle) Backfill(ctx context.Context, options client.ScheduleBackfillOptions) error {
+	_, err := h.call("backfill")
+	return err
+}
+
+func (h *fakeScheduleHandle) Update(ctx context.Context, options client.ScheduleUpdateOptions) error {
+	desc, err := h.Describe(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.call("update"); err != nil {
+		return err
+	}
+
+	_, err = options.DoUpdate(client.ScheduleUpdateInput{Description: *desc})
+
+	return err
+}
+
+func (h *fakeScheduleHandle) Describe(ctx context.Context) (*client.ScheduleDescription, error) {
+	schedule, err := h.get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &client.ScheduleDescription{
+		Schedule: client.Schedule{
+			Spec: &client.ScheduleSpec{},
+		},
+		Memo: toMemo(schedule.memo),
+	}
+
+	desc.Schedule.Policy = newOf(desc.Schedule.Policy)
+	desc.Schedule.State = newOf(desc.Schedule.State)
+	desc.Schedule.State.Paused = schedule.paused
+	desc.Schedule.State.Note = schedule.note
+
+	return desc, nil
+}
+
+func (h *fakeScheduleHandle) Trigger(ctx context.Context, options client.ScheduleTriggerOptions) error {
+	_, err := h.call("trigger")
+	return err
+}
+
+func (h *fakeScheduleHandle) Pause(ctx context.Context, options client.SchedulePauseOptions) error {
+	schedule, err := h.call("pause")
+
+	if err != nil {
+		return err
+	}
+
+	schedule.paused = true
+	schedule.note = options.Note
+
+	return nil
+}
+
+func (h *fakeScheduleHandle) Unpause(ctx context.Context, options client.ScheduleUnpauseOptions) error {
+	schedule, err := h.call("unpause")
+
+	if err != nil {
+		return err
+	}
+
+	schedule.paused = false
+	schedule.note = options.Note
+
+	return nil
+}
+
+// newOf returns a new value of the type of the pointer, for types of the SDK which are not exported.
+func newOf[T any](*T) *T {
+	return new(T)
+}
+
+func toMemo(fields map[string]interface{}) *commonpb.Memo {
+	if fields == nil {
+		return nil
+	}
+
+	res := &commonpb.Memo{Fields: map[string]*commonpb.Payload{}}
+
+	for key, val := range fields {
+		payload, err := converter.GetDefaultDataConverter().ToPayload(val)
+
+		if err != nil {
+			panic(err)
+		}
+
+		res.Fields[key] = payload
+	}
+
+	return res
+}
+
+// fakeTemporalClient is a Temporal client which only implements the schedule client.
+type fakeTemporalClient struct {
+	client.Client
+
+	schedules *fakeSchedules
+}
+
+func (c *fakeTemporalClient) ScheduleClient() client.ScheduleClient {
+	return c.schedules
+}
+
+type fakeClients struct {
+	tc *fakeTemporalClient
+}
+
+func (c *fakeClients) GetClient(queueName string) (client.Client, error) {
+	return c.tc, nil
+}
+
+func (c *fakeClients) GetDefaultQueueName() string {
+	return "default"
+}
+
+func (c *fakeClients) GetNamespace() string {
+	return "default"
+}
+
+// newScheduleTestDispatcher returns a dispatcher for the workflow files which uses the fake schedule client.
+func newScheduleTestDispatcher(t *testing.T, schedules *fakeSchedules, yamlFiles ...string) *Dispatcher {
+	t.Helper()
+
+	files := []*types.WorkflowFile{}
+
+	for _, yamlStr := range yamlFiles {
+		file, err := types.ParseYAML(context.Background(), []byte(yamlStr))
+
+		if err != nil {
+			t.Fatalf("could not parse file: %v", err)
+		}
+
+		files = append(files, &file)
+	}
+
+	return &Dispatcher{
+		c:     &fakeClients{tc: &fakeTemporalClient{schedules: schedules}},
+		files: files,
+	}
+}
+
+const reportsFile = `
+name: reports
+on:
+  cron:
+    schedule: "0 3 * * *"
+jobs:
+  send:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+`
+
+const brokenFile = `
+name: broken
+on:
+  cron:
+    schedule: "0 3 * * *"
+jobs:
+  send:
+    steps:
+      - actionId: a:b
+        timeout: soon
+`
+
+func TestReconcileSchedules(t *testing.T) {
+	reportsMemo := types.RunMemo("reports", "send", "")
+
+	tests := []struct {
+		name     string
+		existing map[string]*fakeSchedule
+		opts     reconcileOpts
+		want     *ScheduleDiff
+		// wantCalls are the calls which change schedules, in order
+		wantCalls []string
+		// wantSchedules is the state of the schedules after reconciling
+		wantSchedules map[string]*fakeSchedule
+	}{
+		{
+			name: "creates declared schedules",
+			want: &ScheduleDiff{Created: []string{"reports/send"}},
+			wantCalls: []string{
+				"create reports/send",
+			},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+			},
+		},
+		{
+			name: "updates tagged schedules",
+			existing: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo, paused: true, note: "paused by ops"},
+			},
+			want:      &ScheduleDiff{Updated: []string{"reports/send"}},
+			wantCalls: []string{"update reports/send"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo, paused: true, note: "paused by ops"},
+			},
+		},
+		{
+			name: "recreates untagged schedules",
+			existing: map[string]*fakeSchedule{
+				"reports/send": {paused: true, note: "paused by ops"},
+			},
+			want:      &ScheduleDiff{Recreated: []string{"reports/send"}},
+			wantCalls: []string{"delete reports/send", "create reports/send"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo, paused: true, note: "paused by ops"},
+			},
+		},
+		{
+			name: "deletes schedules with the legacy ID",
+			existing: map[string]*fakeSchedule{
+				"send": {},
+			},
+			want: &ScheduleDiff{
+				Created: []string{"reports/send"},
+				Deleted: []string{"send"},
+			},
+			wantCalls: []string{"delete send", "create reports/send"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+			},
+		},
+		{
+			name: "keeps schedules with the legacy ID of another job",
+			existing: map[string]*fakeSchedule{
+				"send": {memo: types.RunMemo("reports", "other", "")},
+			},
+			opts:      reconcileOpts{keepOrphans: true},
+			want:      &ScheduleDiff{Created: []string{"reports/send"}},
+			wantCalls: []string{"create reports/send"},
+			wantSchedules: map[string]*fakeSchedule{
+				"send":         {memo: types.RunMemo("reports", "other", "")},
+				"reports/send": {memo: reportsMemo},
+			},
+		},
+		{
+			name: "deletes orphans",
+			existing: map[string]*fakeSchedule{
+				"reports/send":  {memo: reportsMemo},
+				"removed/job":   {memo: types.RunMemo("removed", "job", "")},
+				"manual":        {},
+				"broken/send":   {memo: types.RunMemo("broken", "send", "")},
+				"reports/other": {memo: types.RunMemo("reports", "other", "")},
+			},
+			want: &ScheduleDiff{
+				Updated: []string{"reports/send"},
+				Deleted: []string{"removed/job", "reports/other"},
+			},
+			wantCalls: []string{"update reports/send", "delete removed/job", "delete reports/other"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+				"manual":       {},
+				"broken/send":  {memo: types.RunMemo("broken", "send", "")},
+			},
+		},
+		{
+			name: "pauses orphans",
+			existing: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+				"removed/job":  {memo: types.RunMemo("removed", "job", "")},
+				"removed/done": {memo: types.RunMemo("removed", "done", ""), paused: true, note: "paused by ops"},
+				"manual":       {},
+			},
+			opts: reconcileOpts{pauseOrphans: true},
+			want: &ScheduleDiff{
+				Updated: []string{"reports/send"},
+				Paused:  []string{"removed/job"},
+			},
+			wantCalls: []string{"update reports/send", "pause removed/job"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+				"removed/job":  {memo: types.RunMemo("removed", "job", ""), paused: true, note: orphanPauseNote},
+				"removed/done": {memo: types.RunMemo("removed", "done", ""), paused: true, note: "paused by ops"},
+				"manual":       {},
+			},
+		},
+		{
+			name: "keeps orphans",
+			existing: map[string]*fakeSchedule{
+				"removed/job": {memo: types.RunMemo("removed", "job", "")},
+			},
+			opts:      reconcileOpts{keepOrphans: true},
+			want:      &ScheduleDiff{Created: []string{"reports/send"}},
+			wantCalls: []string{"create reports/send"},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {memo: reportsMemo},
+				"removed/job":  {memo: types.RunMemo("removed", "job", "")},
+			},
+		},
+		{
+			name: "dry run",
+			existing: map[string]*fakeSchedule{
+				"send":        {},
+				"removed/job": {memo: types.RunMemo("removed", "job", "")},
+				"manual":      {},
+			},
+			opts: reconcileOpts{dryRun: true},
+			want: &ScheduleDiff{
+				Created: []string{"reports/send"},
+				Deleted: []string{"removed/job", "send"},
+			},
+			wantCalls: []string{},
+			wantSchedules: map[string]*fakeSchedule{
+				"send":        {},
+				"removed/job": {memo: types.RunMemo("removed", "job", "")},
+				"manual":      {},
+			},
+		},
+		{
+			name: "dry run with untagged schedules",
+			existing: map[string]*fakeSchedule{
+				"reports/send": {},
+			},
+			opts:      reconcileOpts{dryRun: true},
+			want:      &ScheduleDiff{Recreated: []string{"reports/send"}},
+			wantCalls: []string{},
+			wantSchedules: map[string]*fakeSchedule{
+				"reports/send": {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := newFakeSchedules(tt.existing)
+			d := newScheduleTestDispatcher(t, schedules, reportsFile, brokenFile)
+
+			opts := tt.opts
+
+			got, err := d.reconcileSchedules(context.Background(), &opts)
+
+			// the broken file is reported, and its schedules are left unchanged
+			if err == nil || !strings.Contains(err.Error(), "invalid workflow file broken") {
+				t.Errorf("expected an error for the broken file, got %v", err)
+			}
+
+			want := &ScheduleDiff{
+				Created:   append([]string{}, tt.want.Created...),
+				Updated:   append([]string{}, tt.want.Updated...),
+				Recreated: append([]string{}, tt.want.Recreated...),
+				Deleted:   append([]string{}, tt.want.Deleted...),
+				Paused:    append([]string{}, tt.want.Paused...),
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got diff %+v, want %+v", got, want)
+			}
+
+			if !reflect.DeepEqual(schedules.calls, tt.wantCalls) {
+				t.Errorf("got calls %q, want %q", schedules.calls, tt.wantCalls)
+			}
+
+			if !reflect.DeepEqual(schedules.schedules, tt.wantSchedules) {
+				t.Errorf("got schedules %s, want %s", formatSchedules(schedules.schedules), formatSchedules(tt.wantSchedules))
+			}
+		})
+	}
+}
+
+func formatSchedules(schedules map[string]*fakeSchedule) string {
+	res := []string{}
+
+	for id, schedule := range schedules {
+		res = append(res, fmt.Sprintf("%s: %+v", id, *schedule))
+	}
+
+	sort.Strings(res)
+
+	return strings.Join(res, ", ")
+}
+
+func TestManageSchedules(t *testing.T) {
+	existing := func() map[string]*fakeSchedule {
+		return map[string]*fakeSchedule{
+			"reports/send": {memo: types.RunMemo("reports", "send", "")},
+			"removed/job":  {memo: types.RunMemo("removed", "job", ""), paused: true},
+			"manual":       {},
+		}
+	}
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		run       func(d *Dispatcher) error
+		wantErr   string
+		wantCalls []string
+	}{
+		{
+			name:      "pause",
+			run:       func(d *Dispatcher) error { return d.PauseSchedule(context.Background(), "reports/send", "incident") },
+			wantCalls: []string{"pause reports/send"},
+		},
+		{
+			name:      "resume",
+			run:       func(d *Dispatcher) error { return d.ResumeSchedule(context.Background(), "removed/job", "") },
+			wantCalls: []string{"unpause removed/job"},
+		},
+		{
+			name: "trigger",
+			run: func(d *Dispatcher) error {
+				return d.TriggerSchedule(context.Background(), "reports/send", types.OverlapAllowAll)
+			},
+			wantCalls: []string{"trigger reports/send"},
+		},
+		{
+			name: "backfill",
+			run: func(d *Dispatcher) error {
+				return d.BackfillSchedule(context.Background(), "reports/send", start, start.Add(24*time.Hour), "")
+			},
+			wantCalls: []string{"backfill reports/send"},
+		},
+		{
+			name:    "schedules not created by hatchet",
+			run:     func(d *Dispatcher) error { return d.PauseSchedule(context.Background(), "manual", "") },
+			wantErr: "schedule manual is not managed by hatchet",
+		},
+		{
+			name:    "missing schedules",
+			run:     func(d *Dispatcher) error { return d.ResumeSchedule(context.Background(), "missing", "") },
+			wantErr: "schedule missing does not exist",
+		},
+		{
+			name: "invalid overlap policy",
+			run: func(d *Dispatcher) error {
+				return d.TriggerSchedule(context.Background(), "reports/send", "sometimes")
+			},
+			wantErr: `invalid overlap policy "sometimes"`,
+		},
+		{
+			name: "backfill ending before it starts",
+			run: func(d *Dispatcher) error {
+				return d.BackfillSchedule(context.Background(), "reports/send", start, start.Add(-time.Hour), "")
+			},
+			wantErr: "the end of the backfill must be after its start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := newFakeSchedules(existing())
+			d := newScheduleTestDispatcher(t, schedules, reportsFile)
+
+			err := tt.run(d)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			wantCalls := tt.wantCalls
+
+			if wantCalls == nil {
+				wantCalls = []string{}
+			}
+
+			if !reflect.DeepEqual(schedules.calls, wantCalls) {
+				t.Errorf("got calls %q, want %q", schedules.calls, wantCalls)
+			}
+		})
+	}
+}
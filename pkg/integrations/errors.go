@@ -0,0 +1,54 @@
+package integrations
+
+import "fmt"
+
+// DefaultActionErrorType is the error type of an [ActionError] which does not set a type.
+const DefaultActionErrorType = "ActionError"
+
+// ActionError is an error returned from [Integration.PerformAction] which controls how the step is retried. Type is
+// matched against the nonRetryableErrorTypes in the step's retries, and NonRetryable fails the step immediately
+// regardless of its retries.
+type ActionError struct {
+	Type         string
+	NonRetryable bool
+	Err          error
+}
+
+// NewActionError returns an error with the given type, which is retried according to the step's retries.
+func NewActionError(errType string, err error) *ActionError {
+	return &ActionError{
+		Type: errType,
+		Err:  err,
+	}
+}
+
+// NewNonRetryableError returns an error which fails the step without any further retries. Use this for errors
+// which will never succeed on retry, like invalid input.
+func NewNonRetryableError(err error) *ActionError {
+	return &ActionError{
+		Type:         DefaultActionErrorType,
+		NonRetryable: true,
+		Err:          err,
+	}
+}
+
+func (e *ActionError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("action error of type %s", e.GetType())
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// GetType returns the type of the error, falling back to [DefaultActionErrorType].
+func (e *ActionError) GetType() string {
+	if e.Type == "" {
+		return DefaultActionErrorType
+	}
+
+	return e.Type
+}
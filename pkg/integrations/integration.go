@@ -7,3 +7,10 @@ type Integration interface {
 	Actions() []string
 	PerformAction(action types.Action, data map[string]interface{}) (map[string]interface{}, error)
 }
+
+// ActionSchemaProvider can be implemented by an integration to describe the `with` input of its actions. Schemas
+// are JSON Schemas keyed by action verb, and are included in the generated workflow file schema so that editors
+// can validate step inputs.
+type ActionSchemaProvider interface {
+	ActionSchemas() map[string]map[string]interface{}
+}
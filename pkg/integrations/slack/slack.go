@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
 	"github.com/slack-go/slack"
 )
@@ -31,6 +32,55 @@ func (s *SlackIntegration) Actions() []string {
 		"create-channel",
 		"send-message",
 		"add-users-to-channel",
+		"archive-channel",
+	}
+}
+
+func (s *SlackIntegration) ActionSchemas() map[string]map[string]interface{} {
+	channelId := map[string]interface{}{
+		"type": "string",
+	}
+
+	return map[string]map[string]interface{}{
+		"create-channel": {
+			"type":     "object",
+			"required": []string{"channelName"},
+			"properties": map[string]interface{}{
+				"channelName": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		},
+		"add-users-to-channel": {
+			"type":     "object",
+			"required": []string{"channelId", "userIds"},
+			"properties": map[string]interface{}{
+				"channelId": channelId,
+				"userIds": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+			},
+		},
+		"send-message": {
+			"type":     "object",
+			"required": []string{"channelId", "message"},
+			"properties": map[string]interface{}{
+				"channelId": channelId,
+				"message": map[string]interface{}{
+					"type": "string",
+				},
+			},
+		},
+		"archive-channel": {
+			"type":     "object",
+			"required": []string{"channelId"},
+			"properties": map[string]interface{}{
+				"channelId": channelId,
+			},
+		},
 	}
 }
 
@@ -43,8 +93,10 @@ func (s *SlackIntegration) PerformAction(action types.Action, data map[string]in
 		return s.addUsersToChannel(data)
 	case "send-message":
 		return s.sendMessageToChannel(data)
+	case "archive-channel":
+		return s.archiveChannel(data)
 	default:
-		return nil, fmt.Errorf("unsupported action: %s", action)
+		return nil, integrations.NewNonRetryableError(fmt.Errorf("unsupported action: %s", action))
 	}
 }
 
@@ -52,13 +104,13 @@ func (s *SlackIntegration) createChannel(data map[string]interface{}) (map[strin
 	dataName, ok := data["channelName"]
 
 	if !ok || dataName == nil {
-		return nil, errors.New("missing required field: name")
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: name"))
 	}
 
 	name, ok := dataName.(string)
 
 	if !ok {
-		return nil, errors.New("invalid type for field: name")
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: name"))
 	}
 
 	channel, err := s.api.CreateConversation(slack.CreateConversationParams{
@@ -80,25 +132,25 @@ func (s *SlackIntegration) addUsersToChannel(data map[string]interface{}) (map[s
 	channelId, ok := data["channelId"]
 
 	if !ok || channelId == nil {
-		return nil, errors.New("missing required field: channelId")
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: channelId"))
 	}
 
 	channelIdStr, ok := channelId.(string)
 
 	if !ok {
-		return nil, errors.New("invalid type for field: channelId")
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: channelId"))
 	}
 
 	userIds, ok := data["userIds"]
 
 	if !ok || userIds == nil {
-		return nil, errors.New("missing required field: userIds")
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: userIds"))
 	}
 
 	userIdsArr, ok := userIds.([]any)
 
 	if !ok {
-		return nil, errors.New("invalid type for field: userIds")
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: userIds"))
 	}
 
 	userIdsStrArr := make([]string, len(userIdsArr))
@@ -107,7 +159,7 @@ func (s *SlackIntegration) addUsersToChannel(data map[string]interface{}) (map[s
 		userIdStr, ok := userId.(string)
 
 		if !ok {
-			return nil, errors.New("invalid type for field: userIds")
+			return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: userIds"))
 		}
 
 		userIdsStrArr[i] = userIdStr
@@ -126,25 +178,25 @@ func (s *SlackIntegration) sendMessageToChannel(data map[string]interface{}) (ma
 	channelId, ok := data["channelId"]
 
 	if !ok || channelId == nil {
-		return nil, errors.New("missing required field: channelId")
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: channelId"))
 	}
 
 	channelIdStr, ok := channelId.(string)
 
 	if !ok {
-		return nil, errors.New("invalid type for field: channelId")
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: channelId"))
 	}
 
 	message, ok := data["message"]
 
 	if !ok || message == nil {
-		return nil, errors.New("missing required field: message")
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: message"))
 	}
 
 	messageStr, ok := message.(string)
 
 	if !ok {
-		return nil, errors.New("invalid type for field: message")
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: message"))
 	}
 
 	_, _, err := s.api.PostMessage(channelIdStr, slack.MsgOptionText(messageStr, false))
@@ -155,3 +207,25 @@ func (s *SlackIntegration) sendMessageToChannel(data map[string]interface{}) (ma
 
 	return map[string]interface{}{}, nil
 }
+
+func (s *SlackIntegration) archiveChannel(data map[string]interface{}) (map[string]interface{}, error) {
+	channelId, ok := data["channelId"]
+
+	if !ok || channelId == nil {
+		return nil, integrations.NewNonRetryableError(errors.New("missing required field: channelId"))
+	}
+
+	channelIdStr, ok := channelId.(string)
+
+	if !ok {
+		return nil, integrations.NewNonRetryableError(errors.New("invalid type for field: channelId"))
+	}
+
+	err := s.api.ArchiveConversation(channelIdStr)
+
+	if err != nil {
+		return nil, fmt.Errorf("error archiving slack channel: %w", err)
+	}
+
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeySize is the size in bytes of the AES-256 keys used to encrypt secrets files.
+const KeySize = 32
+
+// EncryptedFileProvider reads secrets from a local file encrypted with AES-256-GCM. The decrypted file is a YAML
+// map of secret names to values, like the file read by [FileProvider]. Encrypted files are written with
+// [WriteEncryptedFile], or with the `hatchet secrets` CLI commands.
+type EncryptedFileProvider struct {
+	path string
+	key  []byte
+}
+
+// NewEncryptedFileProvider returns a provider which reads secrets from the encrypted file at path, using a key
+// returned by [GenerateKey] and [ParseKey].
+func NewEncryptedFileProvider(path string, key []byte) *EncryptedFileProvider {
+	return &EncryptedFileProvider{
+		path: path,
+		key:  key,
+	}
+}
+
+func (p *EncryptedFileProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	values, err := ReadEncryptedFile(p.path, p.key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return lookupSecret(values, name, p.path)
+}
+
+// GenerateKey returns a new random key, encoded as base64.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 key returned by [GenerateKey].
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %w", err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// ReadEncryptedFile decrypts the secrets file at path. A file which does not exist contains no secrets.
+func ReadEncryptedFile(path string, key []byte) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read secrets file: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("secrets file %s is not encrypted with this key", path)
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("secrets file %s is not encrypted with this key", path)
+	}
+
+	values, err := parseSecrets(plaintext)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse secrets file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// WriteEncryptedFile encrypts the secrets and writes them to path, replacing any existing file. The file is only
+// readable by the current user.
+func WriteEncryptedFile(path string, key []byte, values map[string]string) error {
+	plaintext, err := yaml.Marshal(values)
+
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(key)
+
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, gcm.Seal(nonce, nonce, plaintext, nil), 0o600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
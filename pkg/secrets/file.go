@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileProvider reads secrets from a YAML or JSON file containing a map of secret names to values.
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider returns a provider which reads secrets from a YAML or JSON file like:
+//
+//	SLACK_TOKEN: xoxb-...
+//	SLACK_TEAM_ID: T0123
+//
+// The file is read each time a secret is resolved, so changes are picked up without restarting the worker.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{
+		path: path,
+	}
+}
+
+func (p *FileProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	data, err := os.ReadFile(p.path)
+
+	if err != nil {
+		return "", fmt.Errorf("could not read secrets file: %w", err)
+	}
+
+	values, err := parseSecrets(data)
+
+	if err != nil {
+		return "", fmt.Errorf("could not parse secrets file %s: %w", p.path, err)
+	}
+
+	return lookupSecret(values, name, p.path)
+}
+
+// DirProvider reads secrets from a directory containing one file per secret, like a Kubernetes secret volume or a
+// Docker secrets mount.
+type DirProvider struct {
+	dir string
+}
+
+// NewDirProvider returns a provider which reads the secret NAME from the file dir/NAME. A single trailing newline
+// is trimmed from the value.
+func NewDirProvider(dir string) *DirProvider {
+	return &DirProvider{
+		dir: dir,
+	}
+}
+
+func (p *DirProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	// secret names are template fields, but check anyway that the name can't escape the directory
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s does not exist in %s", ErrSecretNotFound, name, p.dir)
+	} else if err != nil {
+		return "", fmt.Errorf("could not read secret %s: %w", name, err)
+	}
+
+	val := strings.TrimSuffix(string(data), "\n")
+
+	return strings.TrimSuffix(val, "\r"), nil
+}
+
+func parseSecrets(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func lookupSecret(values map[string]string, name, path string) (string, error) {
+	val, ok := values[name]
+
+	if !ok {
+		return "", fmt.Errorf("%w: %s does not exist in %s", ErrSecretNotFound, name, path)
+	}
+
+	return val, nil
+}
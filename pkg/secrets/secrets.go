@@ -0,0 +1,107 @@
+// Package secrets provides the secret providers which resolve `.secrets.NAME` references in workflow files.
+//
+// Secrets are resolved by the worker when a step's action runs, rather than when the step's input is rendered by
+// the workflow, so that secret values are never written to the workflow's Temporal history. Values are redacted
+// from the outputs and errors of the action.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrSecretNotFound is returned (wrapped) by a [SecretProvider] when a secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// RedactedValue replaces secret values which are redacted by [Redact].
+const RedactedValue = "[redacted]"
+
+// SecretProvider resolves secrets by name.
+type SecretProvider interface {
+	// GetSecret returns the value of the secret with the given name. If the secret does not exist, the error
+	// wraps [ErrSecretNotFound].
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// DefaultEnvPrefix is the prefix of the environment variables read by the default secret provider of the worker,
+// so that workflow files can only read the environment variables which are meant to be secrets.
+const DefaultEnvPrefix = "HATCHET_SECRET_"
+
+// EnvProvider reads secrets from environment variables.
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider returns a provider which reads the secret NAME from the environment variable prefix + NAME. For
+// example, with the prefix [DefaultEnvPrefix], `.secrets.SLACK_TOKEN` reads HATCHET_SECRET_SLACK_TOKEN. With an
+// empty prefix, workflow files can read every environment variable of the worker.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{
+		prefix: prefix,
+	}
+}
+
+func (p *EnvProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	val, ok := os.LookupEnv(p.prefix + name)
+
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, p.prefix+name)
+	}
+
+	return val, nil
+}
+
+// ChainProvider resolves secrets from a list of providers, in order.
+type ChainProvider struct {
+	providers []SecretProvider
+}
+
+// NewChainProvider returns a provider which returns the secret from the first provider which has it. Errors other
+// than [ErrSecretNotFound] are returned immediately.
+func NewChainProvider(providers ...SecretProvider) *ChainProvider {
+	return &ChainProvider{
+		providers: providers,
+	}
+}
+
+func (p *ChainProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	for _, provider := range p.providers {
+		val, err := provider.GetSecret(ctx, name)
+
+		if err == nil {
+			return val, nil
+		}
+
+		if !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// Redact replaces every occurrence of the secret values in s with [RedactedValue]. Longer values are replaced
+// first, so that a value which contains another value is redacted in full.
+func Redact(s string, values []string) string {
+	sorted := make([]string, 0, len(values))
+
+	for _, val := range values {
+		if val != "" {
+			sorted = append(sorted, val)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, val := range sorted {
+		s = strings.ReplaceAll(s, val, RedactedValue)
+	}
+
+	return s
+}
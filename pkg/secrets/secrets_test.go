@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("HATCHET_SECRET_SLACK_TOKEN", "xoxb")
+	t.Setenv("SLACK_TOKEN", "unprefixed")
+
+	tests := []struct {
+		name     string
+		prefix   string
+		secret   string
+		want     string
+		notFound bool
+	}{
+		{name: "prefixed", prefix: DefaultEnvPrefix, secret: "SLACK_TOKEN", want: "xoxb"},
+		{name: "unprefixed variables are not read", prefix: DefaultEnvPrefix, secret: "HOME", notFound: true},
+		{name: "no prefix", prefix: "", secret: "SLACK_TOKEN", want: "unprefixed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEnvProvider(tt.prefix).GetSecret(context.Background(), tt.secret)
+
+			if tt.notFound {
+				if !errors.Is(err, ErrSecretNotFound) {
+					t.Fatalf("got error %v, want %v", err, ErrSecretNotFound)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type mapProvider map[string]string
+
+func (p mapProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if val, ok := p[name]; ok {
+		return val, nil
+	}
+
+	return "", ErrSecretNotFound
+}
+
+type failingProvider struct{}
+
+func (failingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	return "", errors.New("provider unavailable")
+}
+
+func TestChainProvider(t *testing.T) {
+	chain := NewChainProvider(mapProvider{"A": "first"}, mapProvider{"A": "second", "B": "b"})
+
+	for name, want := range map[string]string{"A": "first", "B": "b"} {
+		if got, err := chain.GetSecret(context.Background(), name); err != nil || got != want {
+			t.Errorf("got %q, %v for %s, want %q", got, err, name, want)
+		}
+	}
+
+	if _, err := chain.GetSecret(context.Background(), "C"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrSecretNotFound)
+	}
+
+	failing := NewChainProvider(mapProvider{}, failingProvider{}, mapProvider{"A": "a"})
+
+	if _, err := failing.GetSecret(context.Background(), "A"); err == nil || errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("got error %v, want the error of the failing provider", err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		s      string
+		values []string
+		want   string
+	}{
+		{"token abc", []string{"abc"}, "token [redacted]"},
+		{"abc abcdef", []string{"abc", "abcdef"}, "[redacted] [redacted]"},
+		{"nothing here", []string{""}, "nothing here"},
+	}
+
+	for _, tt := range tests {
+		if got := Redact(tt.s, tt.values); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
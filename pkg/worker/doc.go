@@ -23,6 +23,19 @@ You can register all integrations using the [WithIntegrations] option, which tak
 		),
 	  )
 
+# Resolving Secrets
+
+Workflow files reference secrets using `.secrets.NAME` in `with` values. Secrets are resolved when the action runs, so
+that they are never written to the workflow's history. By default, `.secrets.NAME` is read from the environment variable
+HATCHET_SECRET_NAME, so that workflow files can't read other environment variables of the worker. The provider can be
+changed using the [WithSecretProvider] option:
+
+	  worker.NewWorker(
+		worker.WithSecretProvider(
+		  secrets.NewDirProvider("/var/run/secrets/hatchet"),
+		),
+	  )
+
 # Adding Workflow Files
 
 By default, the worker will load workflow files from the .hatchet directory. You can override this using the [WithWorkflowFiles] option:
@@ -33,6 +46,9 @@ By default, the worker will load workflow files from the .hatchet directory. You
 		),
 	  )
 
+Files in the .hatchet directory which cannot be parsed or are invalid are logged and skipped. To panic on invalid files
+instead, so that a typo does not silently remove a workflow, use the [WithStrictWorkflowFiles] option.
+
 # Connecting to Temporal
 
 By default, the worker will connect to a Temporal instance using the following environment variables, which can be overriden:
@@ -0,0 +1,529 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// newJobWorkflow returns the Temporal workflow which runs the steps of a job sequentially. The needs argument
+// contains the results of the upstream jobs keyed by job name, and is empty when the job was dispatched directly.
+func newJobWorkflow(job types.WorkflowJob, fileEnv map[string]any) func(ctx workflow.Context, input any, needs map[string]any) (*types.JobResult, error) {
+	jobEnv := mergeEnv(fileEnv, job.Env)
+
+	return func(ctx workflow.Context, input any, needs map[string]any) (*types.JobResult, error) {
+		if needs == nil {
+			needs = map[string]any{}
+		}
+
+		steps := map[string]any{}
+
+		if job.If != "" {
+			globalInput, err := datautils.ToJSONMap(input)
+
+			if err != nil {
+				return nil, err
+			}
+
+			shouldRun, err := datautils.EvaluateCondition(datautils.MergeMaps(globalInput, map[string]any{"needs": needs, "env": mergeEnv(jobEnv)}), job.If, withWorkflowTime(ctx))
+
+			if err != nil {
+				return nil, fmt.Errorf("job if: %w", err)
+			}
+
+			if !shouldRun {
+				return &types.JobResult{
+					Status:  types.RunStatusSkipped,
+					Steps:   steps,
+					Outputs: skippedOutputs(job.Outputs),
+				}, nil
+			}
+		}
+
+		sharedInput := map[string]any{
+			"steps": steps,
+			"needs": needs,
+			"env":   mergeEnv(jobEnv),
+		}
+
+		// succeeded steps are tracked in completion order, so that they can be compensated in reverse order
+		succeeded := []types.WorkflowStep{}
+
+		for _, step := range job.Steps {
+			group := []types.WorkflowStep{step}
+
+			if step.IsParallel() {
+				group = step.Parallel
+			}
+
+			groupSucceeded, failedStepID, err := executeSteps(ctx, job, group, input, sharedInput)
+
+			succeeded = append(succeeded, groupSucceeded...)
+
+			if err != nil {
+				return nil, runFailureHandlers(ctx, job, succeeded, failedStepID, err, input, sharedInput)
+			}
+		}
+
+		outputs, err := renderOutputs(ctx, job.Outputs, input, sharedInput)
+
+		if err != nil {
+			return nil, fmt.Errorf("job outputs: %w", err)
+		}
+
+		return &types.JobResult{
+			Status:  types.RunStatusSucceeded,
+			Steps:   steps,
+			Outputs: outputs,
+		}, nil
+	}
+}
+
+// renderOutputs renders the outputs of a job or workflow file against the trigger input merged with data. It
+// returns nil if there are no outputs.
+func renderOutputs(ctx workflow.Context, outputs map[string]any, input any, data map[string]any) (map[string]any, error) {
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+
+	globalInput, err := datautils.ToJSONMap(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := datautils.DeepCopyMap(outputs)
+
+	if err := datautils.RenderTemplateFields(datautils.MergeMaps(globalInput, datautils.DeepCopyMap(data)), res, withWorkflowTime(ctx)); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// skippedOutputs returns the outputs of a skipped job, where every output is null. Downstream jobs and the
+// outputs of the workflow file can then reference the outputs of a job whether or not it ran.
+func skippedOutputs(outputs map[string]any) map[string]any {
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	res := make(map[string]any, len(outputs))
+
+	for key := range outputs {
+		res[key] = nil
+	}
+
+	return res
+}
+
+// executeSteps runs a group of steps concurrently, and records their results in the shared data once all of them
+// complete. Every step in the group sees the shared data from before the group started. It returns the steps
+// which succeeded and, if any step failed, the id of the first failed step and the errors of all failed steps.
+func executeSteps(ctx workflow.Context, job types.WorkflowJob, group []types.WorkflowStep, input any, sharedInput map[string]any) (succeeded []types.WorkflowStep, failedStepID string, err error) {
+	steps := sharedInput["steps"].(map[string]any)
+
+	futures := make([]workflow.Future, len(group))
+	results := make([]map[string]any, len(group))
+
+	var allErrs error
+
+	// start all steps before waiting on any of them, so that they run concurrently. Futures are then waited on
+	// in the order of the group, which keeps the workflow deterministic on replay.
+	for i, step := range group {
+		future, skipped, err := startStep(ctx, job, step, input, sharedInput)
+
+		if err != nil {
+			allErrs = appendError(allErrs, err)
+
+			if failedStepID == "" {
+				failedStepID = step.ID
+			}
+
+			continue
+		}
+
+		// skipped steps are recorded so that later steps can check their status
+		if skipped {
+			results[i] = map[string]any{
+				"status":  string(types.RunStatusSkipped),
+				"outputs": map[string]any{},
+			}
+
+			continue
+		}
+
+		futures[i] = future
+	}
+
+	for i, step := range group {
+		if futures[i] == nil {
+			continue
+		}
+
+		var activityRes any
+
+		if err := futures[i].Get(ctx, &activityRes); err != nil {
+			allErrs = appendError(allErrs, err)
+
+			if failedStepID == "" {
+				failedStepID = step.ID
+			}
+
+			continue
+		}
+
+		results[i] = map[string]any{
+			"status":  string(types.RunStatusSucceeded),
+			"outputs": activityRes,
+		}
+
+		succeeded = append(succeeded, step)
+	}
+
+	// set the outputs in shared data
+	for i, step := range group {
+		if results[i] != nil {
+			steps[step.ID] = results[i]
+		}
+	}
+
+	return succeeded, failedStepID, allErrs
+}
+
+// executeStep runs a single step and waits for its result. It returns skipped=true without running the action if
+// the step's condition is falsy.
+func executeStep(ctx workflow.Context, job types.WorkflowJob, step types.WorkflowStep, input any, sharedInput map[string]any) (result any, skipped bool, err error) {
+	future, skipped, err := startStep(ctx, job, step, input, sharedInput)
+
+	if err != nil || skipped {
+		return nil, skipped, err
+	}
+
+	err = future.Get(ctx, &result)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return result, false, nil
+}
+
+// startStep renders the step input from the trigger input and shared data, and starts the step's action. It
+// returns skipped=true without starting the action if the step's condition is falsy.
+func startStep(ctx workflow.Context, job types.WorkflowJob, step types.WorkflowStep, input any, sharedInput map[string]any) (future workflow.Future, skipped bool, err error) {
+	options, err := getActivityOptions(job, step)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	activityCtx := workflow.WithActivityOptions(ctx, options)
+
+	globalInput, err := datautils.ToJSONMap(input)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	inputMaps := []map[string]any{
+		globalInput,
+		sharedInput,
+	}
+
+	// the step's env is merged into the job's env in sharedInput, overriding its values
+	if step.Env != nil {
+		inputMaps = append(inputMaps, map[string]any{
+			"env": datautils.DeepCopyMap(step.Env),
+		})
+	}
+
+	activityDataInput := datautils.MergeMaps(inputMaps...)
+
+	if step.If != "" {
+		shouldRun, err := datautils.EvaluateCondition(activityDataInput, step.If, withWorkflowTime(ctx))
+
+		if err != nil {
+			return nil, false, fmt.Errorf("step %s if: %w", step.ID, err)
+		}
+
+		if !shouldRun {
+			return nil, true, nil
+		}
+	}
+
+	action, err := types.ParseActionID(step.ActionID)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	integrationVerb := action.IntegrationVerbString()
+
+	if step.ForEach == nil {
+		activityInput, err := renderStepInput(ctx, step, activityDataInput)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return workflow.ExecuteActivity(activityCtx, integrationVerb, activityInput), false, nil
+	}
+
+	items, err := datautils.EvaluateListExpression(activityDataInput, step.ForEach.Items, withWorkflowTime(ctx))
+
+	if err != nil {
+		return nil, false, fmt.Errorf("step %s forEach: %w", step.ID, err)
+	}
+
+	future, settable := workflow.NewFuture(ctx)
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		settable.Set(executeForEach(ctx, workflow.WithActivityOptions(ctx, options), integrationVerb, step, activityDataInput, items))
+	})
+
+	return future, false, nil
+}
+
+// executeForEach runs the action once per item, with at most maxConcurrency items running at once. Once an item
+// fails, no more items are started. It returns the outputs of each item in the order of the items.
+func executeForEach(ctx, activityCtx workflow.Context, integrationVerb string, step types.WorkflowStep, data map[string]any, items []any) ([]any, error) {
+	results := make([]any, len(items))
+
+	maxConcurrency := step.ForEach.MaxConcurrency
+
+	if maxConcurrency <= 0 {
+		maxConcurrency = len(items)
+	}
+
+	selector := workflow.NewSelector(ctx)
+	next := 0
+	running := 0
+
+	var firstErr error
+
+	for {
+		for firstErr == nil && next < len(items) && running < maxConcurrency {
+			index := next
+			next++
+
+			// each item has its own copy of the data, so items do not see each other's values
+			itemData := make(map[string]any, len(data)+1)
+
+			for key, val := range data {
+				itemData[key] = val
+			}
+
+			itemData["forEach"] = map[string]any{
+				"item":  items[index],
+				"index": index,
+			}
+
+			activityInput, err := renderStepInput(ctx, step, itemData)
+
+			if err != nil {
+				firstErr = fmt.Errorf("item %d: %w", index, err)
+				break
+			}
+
+			running++
+
+			selector.AddFuture(workflow.ExecuteActivity(activityCtx, integrationVerb, activityInput), func(f workflow.Future) {
+				running--
+
+				if err := f.Get(ctx, &results[index]); err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("item %d: %w", index, err)
+				}
+			})
+		}
+
+		if running == 0 {
+			break
+		}
+
+		selector.Select(ctx)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return results, nil
+}
+
+// renderStepInput renders the step's `with` values using the data, and merges them into the data to build the
+// input of the action.
+func renderStepInput(ctx workflow.Context, step types.WorkflowStep, data map[string]any) (map[string]any, error) {
+	// if the "With" map is nil, it was not set by the user
+	if step.With == nil {
+		return map[string]any{}, nil
+	}
+
+	// the step definition is shared between runs, so it must not be modified by rendering
+	withData := datautils.DeepCopyMap(step.With)
+
+	if err := datautils.RenderTemplateFields(data, withData, withWorkflowTime(ctx), deferSecrets); err != nil {
+		return nil, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+
+	return datautils.MergeMaps(data, withData), nil
+}
+
+// mergeEnv merges env maps, with later maps overriding the values of earlier maps. The env maps are not modified,
+// and the result is never nil.
+func mergeEnv(envs ...map[string]any) map[string]any {
+	copies := []map[string]any{{}}
+
+	for _, env := range envs {
+		copies = append(copies, datautils.DeepCopyMap(env))
+	}
+
+	return datautils.MergeMaps(copies...)
+}
+
+// withWorkflowTime sets the time returned by the `now` template function to the workflow's deterministic time, so
+// that templates render the same value when the workflow is replayed.
+func withWorkflowTime(ctx workflow.Context) datautils.RenderOptFunc {
+	return datautils.WithNow(workflow.Now(ctx))
+}
+
+// runFailureHandlers runs after a step fails. The compensations of all succeeded steps run in reverse order of
+// completion, followed by the job's onFailure steps. Handlers see the failure under `.failure`, and run even if
+// the job was cancelled. A failing handler does not stop the remaining handlers. It returns the original error,
+// combined with any handler errors.
+func runFailureHandlers(ctx workflow.Context, job types.WorkflowJob, succeeded []types.WorkflowStep, failedStepID string, stepErr error, input any, sharedInput map[string]any) error {
+	ctx, cancel := workflow.NewDisconnectedContext(ctx)
+	defer cancel()
+
+	steps := sharedInput["steps"].(map[string]any)
+
+	sharedInput["failure"] = map[string]any{
+		"step":  failedStepID,
+		"error": errorMessage(stepErr),
+	}
+
+	var handlerErrs error
+
+	for i := len(succeeded) - 1; i >= 0; i-- {
+		step := succeeded[i]
+
+		if step.Compensate == nil {
+			continue
+		}
+
+		_, skipped, err := executeStep(ctx, job, *step.Compensate, input, sharedInput)
+
+		if err != nil {
+			handlerErrs = multierror.Append(handlerErrs, fmt.Errorf("compensation for step %s failed: %w", step.ID, err))
+			continue
+		}
+
+		// a step whose compensation was skipped by its condition keeps its status
+		if skipped {
+			continue
+		}
+
+		steps[step.ID].(map[string]any)["status"] = string(types.RunStatusCompensated)
+	}
+
+	for _, step := range job.OnFailure {
+		activityRes, skipped, err := executeStep(ctx, job, step, input, sharedInput)
+
+		if err != nil {
+			handlerErrs = multierror.Append(handlerErrs, fmt.Errorf("onFailure step %s failed: %w", step.ID, err))
+			continue
+		}
+
+		if !skipped && step.ID != "" {
+			steps[step.ID] = map[string]any{
+				"status":  string(types.RunStatusSucceeded),
+				"outputs": activityRes,
+			}
+		}
+	}
+
+	if handlerErrs != nil {
+		return multierror.Append(stepErr, handlerErrs)
+	}
+
+	return stepErr
+}
+
+// appendError appends err to allErrs. Unlike [multierror.Append], a single error is returned as-is, so that its
+// Temporal error type is preserved.
+func appendError(allErrs error, err error) error {
+	if allErrs == nil {
+		return err
+	}
+
+	return multierror.Append(allErrs, err)
+}
+
+// errorMessage returns the message of the application error which caused err, without the activity details
+// which Temporal adds when wrapping it.
+func errorMessage(err error) string {
+	var appErr *temporal.ApplicationError
+
+	if errors.As(err, &appErr) {
+		return appErr.Message()
+	}
+
+	return err.Error()
+}
+
+// defaultStepTimeout is the timeout for steps which do not set a timeout.
+const defaultStepTimeout = 10 * time.Minute
+
+func getActivityOptions(job types.WorkflowJob, step types.WorkflowStep) (workflow.ActivityOptions, error) {
+	timeout, err := step.GetTimeout()
+
+	if err != nil {
+		return workflow.ActivityOptions{}, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+
+	if timeout == 0 {
+		timeout = defaultStepTimeout
+	}
+
+	retryPolicy, err := getRetryPolicy(job.GetRetries(step))
+
+	if err != nil {
+		return workflow.ActivityOptions{}, fmt.Errorf("step %s: retries: %w", step.ID, err)
+	}
+
+	// the step timeout applies to each attempt, so retries are only bounded by the job timeout
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: timeout,
+		RetryPolicy:         retryPolicy,
+	}, nil
+}
+
+// getRetryPolicy translates retries into a Temporal retry policy. Steps without retries are attempted once.
+func getRetryPolicy(retries *types.WorkflowRetries) (*temporal.RetryPolicy, error) {
+	if retries == nil {
+		return &temporal.RetryPolicy{
+			MaximumAttempts: 1,
+		}, nil
+	}
+
+	policy, err := retries.Parse()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &temporal.RetryPolicy{
+		MaximumAttempts:        policy.MaxAttempts,
+		InitialInterval:        policy.InitialInterval,
+		MaximumInterval:        policy.MaxInterval,
+		BackoffCoefficient:     policy.BackoffCoefficient,
+		NonRetryableErrorTypes: policy.NonRetryableErrorTypes,
+	}, nil
+}
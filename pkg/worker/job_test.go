@@ -0,0 +1,711 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// testActions records the inputs of the actions run by a test job. The `test:record` action returns its `name`
+// and `value` inputs as outputs, and the `test:fail` action fails with its `message` input.
+type testActions struct {
+	mu     sync.Mutex
+	inputs []map[string]any
+}
+
+func (a *testActions) record(ctx context.Context, input any) (any, error) {
+	data := input.(map[string]any)
+
+	a.mu.Lock()
+	a.inputs = append(a.inputs, data)
+	a.mu.Unlock()
+
+	return map[string]any{
+		"name":  data["name"],
+		"value": data["value"],
+	}, nil
+}
+
+func (a *testActions) fail(ctx context.Context, input any) (any, error) {
+	data := input.(map[string]any)
+
+	a.mu.Lock()
+	a.inputs = append(a.inputs, data)
+	a.mu.Unlock()
+
+	return nil, errors.New(fmt.Sprint(data["message"]))
+}
+
+// names returns the `name` input of each action, in the order the actions ran.
+func (a *testActions) names() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	res := []string{}
+
+	for _, input := range a.inputs {
+		res = append(res, fmt.Sprint(input["name"]))
+	}
+
+	return res
+}
+
+// input returns the input of the action with the given `name` input.
+func (a *testActions) input(t *testing.T, name string) map[string]any {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, input := range a.inputs {
+		if input["name"] == name {
+			return input
+		}
+	}
+
+	t.Fatalf("action %s did not run", name)
+
+	return nil
+}
+
+// runTestJob runs the job named "job" of the workflow file in a Temporal test environment, with any extra
+// activities registered by their action ID.
+func runTestJob(t *testing.T, yamlStr string, input any, extra map[string]activityFunc) (*types.JobResult, *testActions, error) {
+	t.Helper()
+
+	file, err := types.ParseYAML(context.Background(), []byte(yamlStr))
+
+	if err != nil {
+		t.Fatalf("could not parse file: %v", err)
+	}
+
+	var suite testsuite.WorkflowTestSuite
+
+	env := suite.NewTestWorkflowEnvironment()
+
+	env.RegisterWorkflowWithOptions(newJobWorkflow(file.Jobs["job"], file.Env), workflow.RegisterOptions{
+		Name: types.JobWorkflowType(file.Name, "job"),
+	})
+
+	actions := &testActions{}
+
+	activities := map[string]activityFunc{
+		"test:record": actions.record,
+		"test:fail":   actions.fail,
+	}
+
+	for name, fn := range extra {
+		activities[name] = fn
+	}
+
+	for name, fn := range activities {
+		env.RegisterActivityWithOptions(fn, activity.RegisterOptions{Name: name})
+	}
+
+	env.ExecuteWorkflow(types.JobWorkflowType(file.Name, "job"), input, map[string]any{})
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatalf("workflow did not complete")
+	}
+
+	if err := env.GetWorkflowError(); err != nil {
+		return nil, actions, err
+	}
+
+	var res types.JobResult
+
+	if err := env.GetWorkflowResult(&res); err != nil {
+		t.Fatalf("could not get workflow result: %v", err)
+	}
+
+	return &res, actions, nil
+}
+
+func TestJobCompensation(t *testing.T) {
+	_, actions, err := runTestJob(t, `
+name: saga
+jobs:
+  job:
+    steps:
+      - id: one
+        actionId: test:record
+        with:
+          name: one
+        compensate:
+          actionId: test:record
+          with:
+            name: undoOne
+      - id: two
+        actionId: test:record
+        with:
+          name: two
+        compensate:
+          actionId: test:record
+          with:
+            name: undoTwo
+            status: "{{ .steps.two.status }}"
+      - id: kept
+        actionId: test:record
+        with:
+          name: kept
+        compensate:
+          actionId: test:record
+          if: "false"
+          with:
+            name: undoKept
+      - id: three
+        actionId: test:fail
+        with:
+          name: three
+          message: card declined
+    onFailure:
+      - id: notify
+        actionId: test:record
+        with:
+          name: notify
+          failedStep: "{{ .failure.step }}"
+          error: "{{ .failure.error }}"
+          statuses: "{{ .steps.one.status }} {{ .steps.two.status }} {{ .steps.kept.status }}"
+`, map[string]any{}, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "card declined") {
+		t.Fatalf("expected the step error, got %v", err)
+	}
+
+	want := []string{"one", "two", "kept", "three", "undoTwo", "undoOne", "notify"}
+
+	if got := actions.names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got actions %q, want %q", got, want)
+	}
+
+	// compensations see the status of the step before it is compensated
+	if got := actions.input(t, "undoTwo")["status"]; got != "succeeded" {
+		t.Errorf("got status %v in compensation, want succeeded", got)
+	}
+
+	notify := actions.input(t, "notify")
+
+	wantNotify := map[string]any{
+		"failedStep": "three",
+		"error":      "card declined",
+		"statuses":   "compensated compensated succeeded",
+	}
+
+	for key, want := range wantNotify {
+		if notify[key] != want {
+			t.Errorf("got %s %v in onFailure step, want %v", key, notify[key], want)
+		}
+	}
+}
+
+// barrier returns an action which records its input and waits until n actions have started, so that it fails
+// unless its actions run concurrently.
+func barrier(actions *testActions, n int) activityFunc {
+	var mu sync.Mutex
+
+	started := 0
+	ready := make(chan struct{})
+
+	return func(ctx context.Context, input any) (any, error) {
+		mu.Lock()
+		started++
+
+		if started == n {
+			close(ready)
+		}
+
+		mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("the other actions of the group did not start")
+		}
+
+		return actions.record(ctx, input)
+	}
+}
+
+func TestJobParallelGroup(t *testing.T) {
+	file := `
+name: parallel
+jobs:
+  job:
+    steps:
+      - id: first
+        actionId: test:record
+        with:
+          name: first
+          value: 1
+      - parallel:
+          - id: left
+            actionId: test:barrier
+            with:
+              name: left
+              value: "{{ .steps.first.outputs.value }}-left"
+          - id: right
+            actionId: test:barrier
+            with:
+              name: right
+              value: "{{ .steps.first.outputs.value }}-right"
+      - id: last
+        actionId: test:record
+        with:
+          name: last
+          value: "{{ .steps.left.outputs.value }} {{ .steps.right.outputs.value }}"
+`
+
+	actions := &testActions{}
+
+	res, recorded, err := runTestJob(t, file, map[string]any{}, map[string]activityFunc{
+		"test:barrier": barrier(actions, 2),
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the group's actions are recorded once both have started, and the last step runs after both
+	groupNames := actions.names()
+	sort.Strings(groupNames)
+
+	if want := []string{"left", "right"}; !reflect.DeepEqual(groupNames, want) {
+		t.Fatalf("got group actions %q, want %q", groupNames, want)
+	}
+
+	if got, want := recorded.names(), []string{"first", "last"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got actions %q, want %q", got, want)
+	}
+
+	if got := recorded.input(t, "last")["value"]; got != "1-left 1-right" {
+		t.Errorf("got value %v in the next step, want the outputs of both group steps", got)
+	}
+
+	for _, id := range []string{"left", "right"} {
+		outputs := res.Steps[id].(map[string]any)["outputs"].(map[string]any)
+
+		if outputs["name"] != id {
+			t.Errorf("got outputs %v under step %s", outputs, id)
+		}
+	}
+}
+
+func TestJobParallelGroupFailure(t *testing.T) {
+	_, actions, err := runTestJob(t, `
+name: parallel
+jobs:
+  job:
+    steps:
+      - id: first
+        actionId: test:record
+        with:
+          name: first
+        compensate:
+          actionId: test:record
+          with:
+            name: undoFirst
+      - parallel:
+          - id: ok
+            actionId: test:record
+            with:
+              name: ok
+            compensate:
+              actionId: test:record
+              with:
+                name: undoOk
+          - id: bad
+            actionId: test:fail
+            with:
+              name: bad
+              message: group failed
+      - id: never
+        actionId: test:record
+        with:
+          name: never
+`, map[string]any{}, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "group failed") {
+		t.Fatalf("expected the group error, got %v", err)
+	}
+
+	got := actions.names()
+
+	// the steps of the group run concurrently, so they can run in either order
+	if len(got) > 3 && got[1] == "bad" {
+		got[1], got[2] = got[2], got[1]
+	}
+
+	if want := []string{"first", "ok", "bad", "undoOk", "undoFirst"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got actions %q, want %q", got, want)
+	}
+}
+
+// inFlight returns an action which sleeps for its `sleep` input in milliseconds and returns its `item` input,
+// and records the maximum number of actions which were running at once. It fails if its `item` input is "fail".
+func inFlight(actions *testActions, maxRunning *int) activityFunc {
+	var mu sync.Mutex
+
+	running := 0
+
+	return func(ctx context.Context, input any) (any, error) {
+		data := input.(map[string]any)
+
+		mu.Lock()
+		running++
+
+		if running > *maxRunning {
+			*maxRunning = running
+		}
+
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		if _, err := actions.record(ctx, input); err != nil {
+			return nil, err
+		}
+
+		sleep, _ := data["sleep"].(float64)
+
+		time.Sleep(time.Duration(sleep) * time.Millisecond)
+
+		if data["item"] == "fail" {
+			return nil, errors.New("item failed")
+		}
+
+		return data["item"], nil
+	}
+}
+
+func TestJobForEach(t *testing.T) {
+	file := `
+name: for-each
+jobs:
+  job:
+    steps:
+      - id: each
+        actionId: test:item
+        forEach:
+          items: .items
+          maxConcurrency: 2
+        with:
+          name: "item {{ .forEach.index }}"
+          item: "${{ .forEach.item }}"
+          sleep: "${{ .forEach.item.sleep }}"
+`
+
+	tests := []struct {
+		name        string
+		items       []any
+		want        []any
+		wantStarted int
+	}{
+		{
+			name: "results keep the order of the items",
+			items: []any{
+				map[string]any{"id": "a", "sleep": 40},
+				map[string]any{"id": "b", "sleep": 10},
+				map[string]any{"id": "c", "sleep": 30},
+				map[string]any{"id": "d", "sleep": 0},
+				map[string]any{"id": "e", "sleep": 20},
+			},
+			want: []any{
+				map[string]any{"id": "a", "sleep": float64(40)},
+				map[string]any{"id": "b", "sleep": float64(10)},
+				map[string]any{"id": "c", "sleep": float64(30)},
+				map[string]any{"id": "d", "sleep": float64(0)},
+				map[string]any{"id": "e", "sleep": float64(20)},
+			},
+			wantStarted: 5,
+		},
+		{
+			name:  "empty list",
+			items: []any{},
+			want:  []any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := &testActions{}
+			maxRunning := 0
+
+			res, _, err := runTestJob(t, file, map[string]any{"items": tt.items}, map[string]activityFunc{
+				"test:item": inFlight(actions, &maxRunning),
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantStarted > 0 && maxRunning != 2 {
+				t.Errorf("got %d items running at once, want 2", maxRunning)
+			}
+
+			if got := len(actions.names()); got != tt.wantStarted {
+				t.Errorf("got %d items started, want %d", got, tt.wantStarted)
+			}
+
+			outputs := res.Steps["each"].(map[string]any)["outputs"]
+
+			if !reflect.DeepEqual(outputs, tt.want) {
+				t.Errorf("got outputs %#v, want %#v", outputs, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobForEachFailure(t *testing.T) {
+	actions := &testActions{}
+	maxRunning := 0
+
+	_, _, err := runTestJob(t, `
+name: for-each
+jobs:
+  job:
+    steps:
+      - id: each
+        actionId: test:item
+        forEach:
+          items: .items
+          maxConcurrency: 1
+        with:
+          name: "item {{ .forEach.index }}"
+          item: "${{ .forEach.item }}"
+`, map[string]any{"items": []any{"a", "fail", "c", "d"}}, map[string]activityFunc{
+		"test:item": inFlight(actions, &maxRunning),
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "item 1: ") {
+		t.Fatalf("expected the error of the second item, got %v", err)
+	}
+
+	// no items are started once an item fails
+	if got, want := actions.names(), []string{"item 0", "item 1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got items %q, want %q", got, want)
+	}
+}
+
+func TestGetActivityOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     types.WorkflowJob
+		step    types.WorkflowStep
+		want    workflow.ActivityOptions
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			step: types.WorkflowStep{ID: "a"},
+			want: workflow.ActivityOptions{
+				StartToCloseTimeout: 10 * time.Minute,
+				RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+			},
+		},
+		{
+			name: "step timeout",
+			step: types.WorkflowStep{ID: "a", Timeout: "1m30s"},
+			want: workflow.ActivityOptions{
+				StartToCloseTimeout: 90 * time.Second,
+				RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+			},
+		},
+		{
+			name: "job retries",
+			job:  types.WorkflowJob{Retries: &types.WorkflowRetries{MaxAttempts: 3, InitialInterval: "2s"}},
+			step: types.WorkflowStep{ID: "a", Timeout: "10s"},
+			want: workflow.ActivityOptions{
+				StartToCloseTimeout: 10 * time.Second,
+				RetryPolicy: &temporal.RetryPolicy{
+					MaximumAttempts: 3,
+					InitialInterval: 2 * time.Second,
+				},
+			},
+		},
+		{
+			name: "step retries override job retries",
+			job:  types.WorkflowJob{Retries: &types.WorkflowRetries{MaxAttempts: 3}},
+			step: types.WorkflowStep{
+				ID:      "a",
+				Retries: &types.WorkflowRetries{MaxAttempts: 5, NonRetryableErrorTypes: []string{"InvalidInput"}},
+			},
+			want: workflow.ActivityOptions{
+				StartToCloseTimeout: 10 * time.Minute,
+				RetryPolicy: &temporal.RetryPolicy{
+					MaximumAttempts:        5,
+					NonRetryableErrorTypes: []string{"InvalidInput"},
+				},
+			},
+		},
+		{
+			name:    "invalid timeout",
+			step:    types.WorkflowStep{ID: "a", Timeout: "10"},
+			wantErr: `step a: invalid timeout "10"`,
+		},
+		{
+			name:    "zero timeout",
+			step:    types.WorkflowStep{ID: "a", Timeout: "0s"},
+			wantErr: "must be greater than 0",
+		},
+		{
+			name:    "invalid retries",
+			step:    types.WorkflowStep{ID: "a", Retries: &types.WorkflowRetries{InitialInterval: "soon"}},
+			wantErr: "step a: retries:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getActivityOptions(tt.job, tt.step)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got options %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	fileEnv := map[string]any{
+		"a":       "file",
+		"b":       "file",
+		"nested":  map[string]any{"x": 1, "y": 1},
+		"removed": "file",
+	}
+
+	jobEnv := map[string]any{
+		"b":       "job",
+		"c":       "job",
+		"nested":  map[string]any{"y": 2},
+		"removed": nil,
+	}
+
+	stepEnv := map[string]any{
+		"c": "step",
+	}
+
+	tests := []struct {
+		name string
+		envs []map[string]any
+		want map[string]any
+	}{
+		{
+			name: "no env",
+			envs: nil,
+			want: map[string]any{},
+		},
+		{
+			name: "file",
+			envs: []map[string]any{fileEnv},
+			want: fileEnv,
+		},
+		{
+			name: "job overrides file",
+			envs: []map[string]any{fileEnv, jobEnv},
+			want: map[string]any{
+				"a":      "file",
+				"b":      "job",
+				"c":      "job",
+				"nested": map[string]any{"x": 1, "y": 2},
+			},
+		},
+		{
+			name: "step overrides job",
+			envs: []map[string]any{fileEnv, jobEnv, stepEnv},
+			want: map[string]any{
+				"a":      "file",
+				"b":      "job",
+				"c":      "step",
+				"nested": map[string]any{"x": 1, "y": 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeEnv(tt.envs...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got env %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if fileEnv["b"] != "file" || !reflect.DeepEqual(fileEnv["nested"], map[string]any{"x": 1, "y": 1}) {
+		t.Errorf("expected the file env to be unchanged, got %v", fileEnv)
+	}
+}
+
+func TestJobEnv(t *testing.T) {
+	_, actions, err := runTestJob(t, `
+name: env
+env:
+  a: file
+  b: file
+  greeting: "Hello {{ .username }}"
+jobs:
+  job:
+    env:
+      b: job
+      c: job
+    steps:
+      - id: withStepEnv
+        actionId: test:record
+        env:
+          c: step
+        with:
+          name: withStepEnv
+          value: "{{ .env.a }} {{ .env.b }} {{ .env.c }}"
+          greeting: "{{ .env.greeting }}"
+      - id: withoutStepEnv
+        actionId: test:record
+        with:
+          name: withoutStepEnv
+          value: "{{ .env.a }} {{ .env.b }} {{ .env.c }}"
+`, map[string]any{"username": "ada"}, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := actions.input(t, "withStepEnv")["value"]; got != "file job step" {
+		t.Errorf("got value %v with a step env, want file job step", got)
+	}
+
+	// the env of a step does not change the env of later steps
+	if got := actions.input(t, "withoutStepEnv")["value"]; got != "file job job" {
+		t.Errorf("got value %v without a step env, want file job job", got)
+	}
+
+	// env values are constants, so templates in them are not rendered
+	if got := actions.input(t, "withStepEnv")["greeting"]; got != "Hello {{ .username }}" {
+		t.Errorf("got greeting %v, want the unrendered env value", got)
+	}
+}
@@ -0,0 +1,257 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/secrets"
+	"go.temporal.io/sdk/activity"
+)
+
+// secretTemplateKey marks a `with` value which references `.secrets`. The workflow leaves these values unrendered,
+// and they are rendered by the activity once secrets have been resolved, so that secret values are never written to
+// the workflow's history.
+const secretTemplateKey = "__hatchetSecretTemplate"
+
+// deferSecrets leaves `with` values which reference `.secrets` to be rendered by the activity.
+var deferSecrets = datautils.WithDeferredRoot("secrets", func(tmplStr string) interface{} {
+	return map[string]any{
+		secretTemplateKey: tmplStr,
+	}
+})
+
+// resolveSecrets renders the values of the action input which reference `.secrets`, and returns the resolved
+// secret values so that they can be redacted from the action's outputs and errors. Errors may contain secret values,
+// and must be redacted by the caller.
+func resolveSecrets(ctx context.Context, provider secrets.SecretProvider, input map[string]any) (map[string]any, []string, error) {
+	names := map[string]bool{}
+
+	if !collectSecretNames(input, names) {
+		return input, nil, nil
+	}
+
+	if provider == nil {
+		return nil, nil, integrations.NewNonRetryableError(errors.New("secrets are referenced, but no secret provider is configured"))
+	}
+
+	resolved := make(map[string]any, len(names))
+	values := make([]string, 0, len(names))
+
+	for _, name := range sortedNames(names) {
+		val, err := provider.GetSecret(ctx, name)
+
+		if errors.Is(err, secrets.ErrSecretNotFound) {
+			return nil, nil, integrations.NewNonRetryableError(fmt.Errorf("could not resolve secret %s: %w", name, err))
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("could not resolve secret %s: %w", name, err)
+		}
+
+		resolved[name] = val
+		values = append(values, val)
+	}
+
+	data := datautils.MergeMaps(input, map[string]any{
+		"secrets": resolved,
+	})
+
+	// rendered values are redacted as well as the secrets, so that secrets transformed by template functions like
+	// b64enc are also redacted
+	rendered, err := renderSecretTemplates(data, "", input, &values, datautils.WithNow(activity.GetInfo(ctx).ScheduledTime))
+
+	if err != nil {
+		return nil, values, integrations.NewNonRetryableError(err)
+	}
+
+	return rendered.(map[string]any), values, nil
+}
+
+// collectSecretNames adds the names of the secrets referenced by the input to names, and returns whether the input
+// references any secrets.
+func collectSecretNames(val any, names map[string]bool) bool {
+	found := false
+
+	switch v := val.(type) {
+	case map[string]any:
+		if tmplStr, ok := v[secretTemplateKey].(string); ok {
+			refs, _ := datautils.TemplateReferences(tmplStr)
+
+			for _, ref := range refs {
+				if len(ref) >= 2 && ref[0] == "secrets" {
+					names[ref[1]] = true
+				}
+			}
+
+			return true
+		}
+
+		for _, item := range v {
+			found = collectSecretNames(item, names) || found
+		}
+	case []any:
+		for _, item := range v {
+			found = collectSecretNames(item, names) || found
+		}
+	}
+
+	return found
+}
+
+// renderSecretTemplates returns a copy of the value, with each deferred template rendered using the data. Rendered
+// strings are appended to rendered.
+func renderSecretTemplates(data map[string]any, path string, val any, rendered *[]string, opts ...datautils.RenderOptFunc) (any, error) {
+	switch v := val.(type) {
+	case map[string]any:
+		if tmplStr, ok := v[secretTemplateKey].(string); ok {
+			res, err := datautils.RenderValue(data, path, tmplStr, opts...)
+
+			if str, ok := res.(string); ok && err == nil {
+				*rendered = append(*rendered, str)
+			}
+
+			return res, err
+		}
+
+		res := make(map[string]any, len(v))
+
+		for key, item := range v {
+			itemPath := key
+
+			if path != "" {
+				itemPath = path + "." + key
+			}
+
+			renderedItem, err := renderSecretTemplates(data, itemPath, item, rendered, opts...)
+
+			if err != nil {
+				return nil, err
+			}
+
+			res[key] = renderedItem
+		}
+
+		return res, nil
+	case []any:
+		res := make([]any, len(v))
+
+		for i, item := range v {
+			renderedItem, err := renderSecretTemplates(data, fmt.Sprintf("%s[%d]", path, i), item, rendered, opts...)
+
+			if err != nil {
+				return nil, err
+			}
+
+			res[i] = renderedItem
+		}
+
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+// redactResult replaces secret values in the outputs of an action, so that they are not written to the workflow's
+// history. Strings in the outputs are redacted one by one, so that the structure of the outputs is kept even if a
+// secret value matches a key or a number.
+func redactResult(result any, values []string) (any, error) {
+	if len(values) == 0 || result == nil {
+		return result, nil
+	}
+
+	// outputs are decoded from JSON, so that the strings of structs are redacted as well
+	resultBytes, err := json.Marshal(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+
+	if err := json.Unmarshal(resultBytes, &decoded); err != nil {
+		return nil, err
+	}
+
+	res, redacted := redactValue(decoded, values)
+
+	if !redacted {
+		return result, nil
+	}
+
+	return res, nil
+}
+
+// redactValue returns a copy of the value with the secret values replaced in every string, and whether any string
+// was redacted.
+func redactValue(val any, values []string) (any, bool) {
+	switch v := val.(type) {
+	case string:
+		res := secrets.Redact(v, values)
+		return res, res != v
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		redacted := false
+
+		for key, item := range v {
+			redactedItem, itemRedacted := redactValue(item, values)
+			res[key] = redactedItem
+			redacted = redacted || itemRedacted
+		}
+
+		return res, redacted
+	case []any:
+		res := make([]any, len(v))
+		redacted := false
+
+		for i, item := range v {
+			redactedItem, itemRedacted := redactValue(item, values)
+			res[i] = redactedItem
+			redacted = redacted || itemRedacted
+		}
+
+		return res, redacted
+	default:
+		return v, false
+	}
+}
+
+// redactError replaces secret values in an error message. The type and retryability of an
+// [integrations.ActionError] are kept.
+func redactError(err error, values []string) error {
+	if err == nil || len(values) == 0 {
+		return err
+	}
+
+	msg := secrets.Redact(err.Error(), values)
+
+	if msg == err.Error() {
+		return err
+	}
+
+	var actionErr *integrations.ActionError
+
+	if errors.As(err, &actionErr) {
+		return &integrations.ActionError{
+			Type:         actionErr.Type,
+			NonRetryable: actionErr.NonRetryable,
+			Err:          errors.New(msg),
+		}
+	}
+
+	return errors.New(msg)
+}
+
+func sortedNames(names map[string]bool) []string {
+	res := make([]string, 0, len(names))
+
+	for name := range names {
+		res = append(res, name)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
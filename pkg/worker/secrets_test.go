@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
+)
+
+func TestRedactResult(t *testing.T) {
+	type output struct {
+		Token string `json:"token"`
+		Count int    `json:"count"`
+	}
+
+	tests := []struct {
+		name   string
+		result any
+		values []string
+		want   any
+	}{
+		{
+			name:   "no secrets",
+			result: map[string]any{"token": "abc"},
+			want:   map[string]any{"token": "abc"},
+		},
+		{
+			name:   "nothing to redact keeps the result",
+			result: map[string]any{"count": 1},
+			values: []string{"abc"},
+			want:   map[string]any{"count": 1},
+		},
+		{
+			name: "nested strings",
+			result: map[string]any{
+				"header":   "Bearer abc",
+				"messages": []any{"abc", map[string]any{"text": "the token is abc."}},
+			},
+			values: []string{"abc"},
+			want: map[string]any{
+				"header":   "Bearer [redacted]",
+				"messages": []any{"[redacted]", map[string]any{"text": "the token is [redacted]."}},
+			},
+		},
+		{
+			name:   "keys and numbers are kept",
+			result: map[string]any{"token": "token", "count": 1, "enabled": true},
+			values: []string{"token", "1", "true"},
+			want:   map[string]any{"token": "[redacted]", "count": float64(1), "enabled": true},
+		},
+		{
+			name:   "values with quotes and newlines",
+			result: map[string]any{"key": "-----BEGIN KEY-----\n\"secret\"\n-----END KEY-----"},
+			values: []string{"-----BEGIN KEY-----\n\"secret\"\n-----END KEY-----"},
+			want:   map[string]any{"key": "[redacted]"},
+		},
+		{
+			name:   "longer values first",
+			result: map[string]any{"token": "abcdef"},
+			values: []string{"abc", "abcdef"},
+			want:   map[string]any{"token": "[redacted]"},
+		},
+		{
+			name:   "structs",
+			result: output{Token: "Bearer abc", Count: 2},
+			values: []string{"abc"},
+			want:   map[string]any{"token": "Bearer [redacted]", "count": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redactResult(tt.result, tt.values)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	plain := errors.New("invalid token abc")
+
+	actionErr := &integrations.ActionError{
+		Type:         "Unauthorized",
+		NonRetryable: true,
+		Err:          errors.New("invalid token abc"),
+	}
+
+	if got := redactError(plain, []string{"xyz"}); got != plain {
+		t.Errorf("got %v, want the error unchanged", got)
+	}
+
+	if got := redactError(plain, []string{"abc"}); got.Error() != "invalid token [redacted]" {
+		t.Errorf("got %q, want the secret redacted", got)
+	}
+
+	var got *integrations.ActionError
+
+	if !errors.As(redactError(actionErr, []string{"abc"}), &got) {
+		t.Fatal("expected an action error")
+	}
+
+	if got.Type != "Unauthorized" || !got.NonRetryable || got.Err.Error() != "invalid token [redacted]" {
+		t.Errorf("got %+v, want the type and retryability kept and the secret redacted", got)
+	}
+}
+
+func TestCollectSecretNames(t *testing.T) {
+	input := map[string]any{
+		"channel": "general",
+		"token":   map[string]any{secretTemplateKey: "Bearer {{ .secrets.SLACK_TOKEN }}"},
+		"headers": []any{
+			map[string]any{secretTemplateKey: "{{ .secrets.API_KEY }}-{{ .secrets.SLACK_TOKEN }}"},
+		},
+	}
+
+	names := map[string]bool{}
+
+	if !collectSecretNames(input, names) {
+		t.Fatal("expected secrets to be found")
+	}
+
+	if got, want := sortedNames(names), []string{"API_KEY", "SLACK_TOKEN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if collectSecretNames(map[string]any{"channel": "general"}, map[string]bool{}) {
+		t.Error("expected no secrets to be found")
+	}
+}
@@ -2,13 +2,13 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/hatchet-dev/hatchet-workflows/internal/config/loader"
-	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
 	hatchetclient "github.com/hatchet-dev/hatchet-workflows/pkg/client"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/secrets"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/fileutils"
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
 	"go.temporal.io/sdk/activity"
@@ -34,6 +34,8 @@ type workerOptions struct {
 
 	filesLoader  func() []*types.WorkflowFile
 	clientLoader func(queueName string) client.Client
+
+	secretProvider secrets.SecretProvider
 }
 
 func defaultWorkerOptions() *workerOptions {
@@ -56,11 +58,12 @@ func defaultWorkerOptions() *workerOptions {
 	}
 
 	return &workerOptions{
-		Options:      &worker.Options{},
-		queueName:    hatchetclient.HatchetDefaultQueueName,
-		activities:   make(activities),
-		clientLoader: clientLoader,
-		filesLoader:  fileutils.DefaultLoader,
+		Options:        &worker.Options{},
+		queueName:      hatchetclient.HatchetDefaultQueueName,
+		activities:     make(activities),
+		clientLoader:   clientLoader,
+		filesLoader:    fileutils.DefaultLoader,
+		secretProvider: secrets.NewEnvProvider(secrets.DefaultEnvPrefix),
 	}
 }
 
@@ -86,6 +89,14 @@ func WithWorkflowFiles(files []*types.WorkflowFile) workerOptFunc {
 	}
 }
 
+// WithStrictWorkflowFiles loads the workflow files from the .hatchet folder in the current directory like the default
+// loader, but panics if any file is invalid instead of logging and skipping it.
+func WithStrictWorkflowFiles() workerOptFunc {
+	return func(opts *workerOptions) {
+		opts.filesLoader = fileutils.StrictLoader
+	}
+}
+
 // WithQueueName sets the queue name to use for the worker. Note that this will override the queue name set in the default Temporal client,
 // but will not override the queue name set in the Temporal client passed in with [WithTemporalClient].
 func WithQueueName(queueName string) workerOptFunc {
@@ -94,6 +105,14 @@ func WithQueueName(queueName string) workerOptFunc {
 	}
 }
 
+// WithSecretProvider sets the provider which resolves `.secrets.NAME` references in workflow files. By default,
+// secrets are read from environment variables with the HATCHET_SECRET_ prefix, like HATCHET_SECRET_NAME.
+func WithSecretProvider(provider secrets.SecretProvider) workerOptFunc {
+	return func(opts *workerOptions) {
+		opts.secretProvider = provider
+	}
+}
+
 // WithIntegrations registers all integrations with the worker. See [integrations.Integration] to see the interface
 // integrations must satisfy.
 func WithIntegrations(ints ...integrations.Integration) workerOptFunc {
@@ -107,10 +126,23 @@ func WithIntegrations(ints ...integrations.Integration) workerOptFunc {
 				fmt.Println("registering action", intCp.GetId()+":"+actionCp)
 
 				opts.activities[intCp.GetId()+":"+actionCp] = func(ctx context.Context, input any) (result any, err error) {
-					return intCp.PerformAction(types.Action{
+					// the secret provider is read when the action runs, as it may be set by a later option
+					actionInput, secretValues, err := resolveSecrets(ctx, opts.secretProvider, input.(map[string]any))
+
+					if err != nil {
+						return nil, toActivityError(redactError(err, secretValues))
+					}
+
+					res, err := intCp.PerformAction(types.Action{
 						IntegrationID: intCp.GetId(),
 						Verb:          actionCp,
-					}, input.(map[string]any))
+					}, actionInput)
+
+					if err != nil {
+						return nil, toActivityError(redactError(err, secretValues))
+					}
+
+					return redactResult(res, secretValues)
 				}
 			}
 		}
@@ -131,87 +163,41 @@ func NewWorker(opts ...workerOptFunc) (Worker, error) {
 
 	workflowFiles := workerOptions.filesLoader()
 
-	// register all workflow with the worker
-	for _, workflowFile := range workflowFiles {
-		for jobName, job := range workflowFile.Jobs {
-			jobCp := job
-
-			temporalWorkflow := func(ctx workflow.Context, input any) (result []byte, err error) {
-				retrypolicy := &temporal.RetryPolicy{
-					MaximumAttempts: 1,
-				}
-
-				options := workflow.ActivityOptions{
-					ScheduleToCloseTimeout: 10 * time.Minute,
-					StartToCloseTimeout:    10 * time.Minute,
-					RetryPolicy:            retrypolicy,
-				}
-
-				sharedInput := map[string]any{
-					"steps": map[string]any{},
-				}
-
-				activityCtx := workflow.WithActivityOptions(ctx, options)
-
-				for _, step := range jobCp.Steps {
-					var activityRes any
-
-					globalInput, err := datautils.ToJSONMap(input)
-
-					if err != nil {
-						return nil, err
-					}
-
-					inputMaps := []map[string]any{
-						globalInput,
-						sharedInput,
-					}
-
-					activityInput := map[string]any{}
-
-					// if the "With" map is not nil, it was set by the user
-					if step.With != nil {
-						activityDataInput := datautils.MergeMaps(inputMaps...)
-
-						withData := step.With
-
-						datautils.RenderTemplateFields(activityDataInput, withData)
-
-						activityInput = datautils.MergeMaps(activityDataInput, withData)
-					}
-
-					action, err := types.ParseActionID(step.ActionID)
-
-					if err != nil {
-						return nil, err
-					}
+	// registering two workflows with the same name panics, so file names are checked across files first
+	if err := types.ValidateFiles(workflowFiles); err != nil {
+		return nil, err
+	}
 
-					integrationVerb := action.IntegrationVerbString()
+	// activities are shared between jobs, so they must only be registered once
+	registeredActivities := make(map[string]bool)
 
-					err = workflow.ExecuteActivity(activityCtx, integrationVerb, activityInput).Get(activityCtx, &activityRes)
+	// register all workflow with the worker
+	for _, workflowFile := range workflowFiles {
+		if err := workflowFile.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid workflow file %s: %w", workflowFile.Name, err)
+		}
 
-					if err != nil {
-						// TODO: call any recovery activities
-						return nil, err
-					}
+		tree, err := types.ParseWorkflowTreeFromFile(*workflowFile)
 
-					// set the output in shared data
-					sharedInput["steps"].(map[string]any)[step.ID] = map[string]any{
-						"outputs": activityRes,
-					}
-				}
+		if err != nil {
+			return nil, fmt.Errorf("invalid workflow file %s: %w", workflowFile.Name, err)
+		}
 
-				return nil, nil
-			}
+		// files with job dependencies or outputs are run by a single workflow which starts each job once its
+		// dependencies succeed
+		if workflowFile.UsesWorkflowRun() {
+			workerInstance.RegisterWorkflowWithOptions(newWorkflowRun(workflowFile, tree), workflow.RegisterOptions{
+				Name: workflowFile.Name,
+			})
+		}
 
-			workerInstance.RegisterWorkflowWithOptions(temporalWorkflow, workflow.RegisterOptions{
-				Name: jobName,
+		for jobName, job := range workflowFile.Jobs {
+			workerInstance.RegisterWorkflowWithOptions(newJobWorkflow(job, workflowFile.Env), workflow.RegisterOptions{
+				Name: types.JobWorkflowType(workflowFile.Name, jobName),
 			})
 
 			// register all activities for the job
-			registeredActivities := make(map[string]bool)
-
-			for _, step := range job.Steps {
+			for _, step := range job.ListAllSteps() {
 				action, err := types.ParseActionID(step.ActionID)
 
 				if err != nil {
@@ -240,3 +226,19 @@ func NewWorker(opts ...workerOptFunc) (Worker, error) {
 
 	return workerInstance, nil
 }
+
+// toActivityError converts an [integrations.ActionError] to a Temporal application error, so that its type and
+// retryability are respected by the step's retry policy.
+func toActivityError(err error) error {
+	var actionErr *integrations.ActionError
+
+	if !errors.As(err, &actionErr) {
+		return err
+	}
+
+	if actionErr.NonRetryable {
+		return temporal.NewNonRetryableApplicationError(err.Error(), actionErr.GetType(), err)
+	}
+
+	return temporal.NewApplicationErrorWithCause(err.Error(), actionErr.GetType(), err)
+}
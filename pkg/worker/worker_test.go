@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
+)
+
+func TestToActivityError(t *testing.T) {
+	cause := errors.New("channel already exists")
+
+	tests := []struct {
+		name             string
+		err              error
+		wantType         string
+		wantNonRetryable bool
+	}{
+		{
+			name:     "typed errors keep their type",
+			err:      integrations.NewActionError("Conflict", cause),
+			wantType: "Conflict",
+		},
+		{
+			name:             "non-retryable errors are not retried",
+			err:              integrations.NewNonRetryableError(cause),
+			wantType:         integrations.DefaultActionErrorType,
+			wantNonRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var appErr *temporal.ApplicationError
+
+			if !errors.As(toActivityError(tt.err), &appErr) {
+				t.Fatalf("expected an application error")
+			}
+
+			if appErr.Type() != tt.wantType {
+				t.Errorf("got type %q, want %q", appErr.Type(), tt.wantType)
+			}
+
+			if appErr.NonRetryable() != tt.wantNonRetryable {
+				t.Errorf("got non-retryable %v, want %v", appErr.NonRetryable(), tt.wantNonRetryable)
+			}
+
+			// the cause is kept, rather than being serialized as details
+			if !errors.Is(appErr, cause) {
+				t.Errorf("expected the cause to be wrapped")
+			}
+
+			if appErr.HasDetails() {
+				t.Errorf("expected no details")
+			}
+		})
+	}
+
+	if err := toActivityError(cause); err != cause {
+		t.Errorf("expected errors which are not action errors to be returned unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// newWorkflowRun returns the Temporal workflow which runs all jobs of a workflow file with job dependencies or
+// outputs. Each job runs as a child workflow once all of the jobs it needs have succeeded, and receives their
+// results. Jobs which need a failed or skipped job are skipped. Once every job has completed, the outputs of the
+// file are rendered from the job results. The run key makes the workflow IDs of the jobs unique, and defaults to
+// the run ID if empty.
+func newWorkflowRun(file *types.WorkflowFile, tree *types.WorkflowTree) func(ctx workflow.Context, input any, runKey string) (*types.RunResult, error) {
+	return func(ctx workflow.Context, input any, runKey string) (*types.RunResult, error) {
+		results := map[string]*types.JobResult{}
+		failed := map[string]bool{}
+		started := map[string]bool{}
+
+		var allErrs error
+
+		if runKey == "" {
+			runKey = workflow.GetInfo(ctx).WorkflowExecution.RunID
+		}
+
+		// jobs are started with the event of the run, so that they can be found by event
+		event := memoString(workflow.GetInfo(ctx), types.MemoEvent)
+
+		selector := workflow.NewSelector(ctx)
+		running := 0
+
+		startReadyJobs := func() {
+			// nodes are in topological order, so skips propagate to all downstream jobs in a single pass
+			for _, node := range tree.Nodes() {
+				if started[node.Name] {
+					continue
+				}
+
+				ready := true
+				skip := false
+
+				for _, parent := range node.Parents {
+					if failed[parent.Name] {
+						skip = true
+						continue
+					}
+
+					res, completed := results[parent.Name]
+
+					if !completed {
+						ready = false
+					} else if res.Status == types.RunStatusSkipped {
+						skip = true
+					}
+				}
+
+				jobName := node.Name
+
+				if skip && ready {
+					started[jobName] = true
+					results[jobName] = &types.JobResult{
+						Status: types.RunStatusSkipped,
+						Steps:  map[string]any{},
+					}
+
+					continue
+				}
+
+				if !ready {
+					continue
+				}
+
+				job := file.Jobs[jobName]
+
+				needs := map[string]any{}
+
+				for _, parent := range node.Parents {
+					needs[parent.Name] = results[parent.Name]
+				}
+
+				// timeouts are validated when the worker is created
+				timeout, _ := job.GetTimeout()
+
+				started[jobName] = true
+
+				workflowID, err := file.RenderJobWorkflowID(input, jobName, runKey)
+
+				if err != nil {
+					failed[jobName] = true
+					allErrs = multierror.Append(allErrs, err)
+					continue
+				}
+
+				childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+					WorkflowID:         workflowID,
+					TaskQueue:          job.Queue,
+					WorkflowRunTimeout: timeout,
+					Memo:               types.RunMemo(file.Name, jobName, event),
+				})
+
+				running++
+
+				selector.AddFuture(workflow.ExecuteChildWorkflow(childCtx, types.JobWorkflowType(file.Name, jobName), input, needs), func(f workflow.Future) {
+					running--
+
+					var res types.JobResult
+
+					if err := f.Get(ctx, &res); err != nil {
+						failed[jobName] = true
+						allErrs = multierror.Append(allErrs, fmt.Errorf("job %s failed: %w", jobName, err))
+						return
+					}
+
+					results[jobName] = &res
+				})
+			}
+		}
+
+		startReadyJobs()
+
+		for running > 0 {
+			selector.Select(ctx)
+			startReadyJobs()
+		}
+
+		if allErrs != nil {
+			return nil, allErrs
+		}
+
+		jobs, err := datautils.ToJSONMap(results)
+
+		if err != nil {
+			return nil, err
+		}
+
+		outputs, err := renderOutputs(ctx, file.Outputs, input, map[string]any{
+			"jobs": jobs,
+			"env":  mergeEnv(file.Env),
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("outputs: %w", err)
+		}
+
+		return &types.RunResult{
+			Jobs:    results,
+			Outputs: outputs,
+		}, nil
+	}
+}
+
+// memoString returns a string field from the memo of the workflow, or an empty string if the field is not set.
+func memoString(info *workflow.Info, key string) string {
+	if info.Memo == nil {
+		return ""
+	}
+
+	payload, exists := info.Memo.Fields[key]
+
+	if !exists {
+		return ""
+	}
+
+	var res string
+
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &res); err != nil {
+		return ""
+	}
+
+	return res
+}
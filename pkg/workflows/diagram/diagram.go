@@ -0,0 +1,260 @@
+// Package diagram renders workflow files as Mermaid and Graphviz DOT diagrams. Diagrams show the triggers of a file,
+// each job with its steps in the order they run, and the dependencies between jobs.
+package diagram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// Format is a diagram format supported by [Render].
+type Format string
+
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDOT     Format = "dot"
+)
+
+// Render renders a workflow file in the given format.
+func Render(file *types.WorkflowFile, format Format) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(file)
+	case FormatDOT:
+		return DOT(file)
+	default:
+		return "", fmt.Errorf("unsupported diagram format %q: must be %s or %s", format, FormatMermaid, FormatDOT)
+	}
+}
+
+// graph is the format-independent layout of a workflow file. Triggers are top-level nodes, and each job is a
+// cluster containing its steps.
+type graph struct {
+	name     string
+	triggers []*node
+	jobs     []*cluster
+	edges    []*edge
+}
+
+type node struct {
+	id    string
+	lines []string
+}
+
+type cluster struct {
+	id    string
+	label string
+	nodes []*node
+
+	// first and last are the nodes which edges into and out of the cluster are attached to
+	first, last *node
+}
+
+type edge struct {
+	from, to *node
+
+	// fromCluster and toCluster are set when the edge starts or ends at a job rather than a step
+	fromCluster, toCluster *cluster
+
+	label  string
+	dashed bool
+}
+
+func newGraph(file *types.WorkflowFile) (*graph, error) {
+	tree, err := types.ParseWorkflowTreeFromFile(*file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	g := &graph{
+		name: file.Name,
+	}
+
+	for i, event := range file.On.Events {
+		g.triggers = append(g.triggers, &node{
+			id:    fmt.Sprintf("t%d", i),
+			lines: eventLines(event),
+		})
+	}
+
+	for _, schedule := range file.On.ListSchedules() {
+		g.triggers = append(g.triggers, &node{
+			id:    fmt.Sprintf("t%d", len(g.triggers)),
+			lines: []string{"cron: " + schedule.String()},
+		})
+	}
+
+	clusters := map[string]*cluster{}
+
+	for i, treeNode := range tree.Nodes() {
+		job := file.Jobs[treeNode.Name]
+		c := g.addJob(fmt.Sprintf("j%d", i), treeNode.Name, job)
+
+		clusters[treeNode.Name] = c
+
+		label := ""
+
+		if job.If != "" {
+			label = "if " + strings.TrimSpace(job.If)
+		}
+
+		// jobs without dependencies start on every trigger, and other jobs start once the jobs they need succeed
+		if len(treeNode.Parents) == 0 {
+			for _, trigger := range g.triggers {
+				g.edges = append(g.edges, &edge{from: trigger, to: c.first, toCluster: c, label: label})
+			}
+
+			continue
+		}
+
+		for _, parent := range treeNode.Parents {
+			parentCluster := clusters[parent.Name]
+
+			g.edges = append(g.edges, &edge{
+				from:        parentCluster.last,
+				to:          c.first,
+				fromCluster: parentCluster,
+				toCluster:   c,
+				label:       label,
+			})
+		}
+	}
+
+	return g, nil
+}
+
+// addJob adds the cluster for a job, with edges between its steps in the order they run.
+func (g *graph) addJob(id, jobName string, job types.WorkflowJob) *cluster {
+	c := &cluster{
+		id:    id,
+		label: "job: " + jobName,
+	}
+
+	// previous contains the nodes which run before the next step: a single step, or every step of a parallel group
+	previous := []*node{}
+
+	for i, step := range job.Steps {
+		group := []types.WorkflowStep{step}
+
+		if step.IsParallel() {
+			group = step.Parallel
+		}
+
+		current := []*node{}
+
+		for j, groupStep := range group {
+			stepID := fmt.Sprintf("%ss%d", id, i)
+
+			if step.IsParallel() {
+				stepID = fmt.Sprintf("%ss%dp%d", id, i, j)
+			}
+
+			stepNode := g.addStep(c, stepID, groupStep)
+
+			for _, prev := range previous {
+				g.edges = append(g.edges, &edge{from: prev, to: stepNode})
+			}
+
+			current = append(current, stepNode)
+		}
+
+		if c.first == nil && len(current) > 0 {
+			c.first = current[0]
+		}
+
+		if len(current) > 0 {
+			previous = current
+		}
+	}
+
+	if len(previous) > 0 {
+		c.last = previous[len(previous)-1]
+	}
+
+	var prevFailure *node
+
+	for i, step := range job.OnFailure {
+		failureNode := g.addStep(c, fmt.Sprintf("%sf%d", id, i), step)
+
+		if prevFailure != nil {
+			g.edges = append(g.edges, &edge{from: prevFailure, to: failureNode})
+		} else if c.last != nil {
+			g.edges = append(g.edges, &edge{from: c.last, to: failureNode, label: "on failure", dashed: true})
+		}
+
+		prevFailure = failureNode
+	}
+
+	// jobs always have steps once validated, but an empty job still needs a node for edges to attach to
+	if c.first == nil {
+		empty := &node{id: id + "e", lines: []string{"no steps"}}
+		c.nodes = append(c.nodes, empty)
+		c.first, c.last = empty, empty
+	}
+
+	g.jobs = append(g.jobs, c)
+
+	return c
+}
+
+func (g *graph) addStep(c *cluster, id string, step types.WorkflowStep) *node {
+	stepNode := &node{
+		id:    id,
+		lines: stepLines(step),
+	}
+
+	c.nodes = append(c.nodes, stepNode)
+
+	if step.Compensate != nil {
+		compensateNode := &node{
+			id:    id + "c",
+			lines: stepLines(*step.Compensate),
+		}
+
+		c.nodes = append(c.nodes, compensateNode)
+		g.edges = append(g.edges, &edge{from: stepNode, to: compensateNode, label: "compensate", dashed: true})
+	}
+
+	return stepNode
+}
+
+// stepLines returns the label of a step: its id or name, its action, and its condition and forEach items if set.
+func stepLines(step types.WorkflowStep) []string {
+	title := step.ID
+
+	if title == "" {
+		title = step.Name
+	}
+
+	res := []string{}
+
+	if title != "" {
+		res = append(res, title)
+	}
+
+	res = append(res, step.ActionID)
+
+	if step.If != "" {
+		res = append(res, "if "+strings.TrimSpace(step.If))
+	}
+
+	if step.ForEach != nil {
+		res = append(res, "for each in "+step.ForEach.Items)
+	}
+
+	return res
+}
+
+// eventLines returns the label of an event trigger: the event name, and its filter if set.
+func eventLines(event types.WorkflowEvent) []string {
+	res := []string{"event: " + event.Name}
+
+	if event.If != "" {
+		res = append(res, "if "+strings.TrimSpace(event.If))
+	}
+
+	return res
+}
@@ -0,0 +1,97 @@
+package diagram
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the diagrams")
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "simple", path: "../../../examples/simple/.hatchet/sample-workflow.yaml"},
+		{name: "slack-notifier", path: "../../../examples/slack-notifier/.hatchet/slack-channel.yaml"},
+		// orders has parallel groups, compensations, onFailure steps and labels which need escaping
+		{name: "orders", path: "testdata/orders.yaml"},
+	}
+
+	for _, tt := range tests {
+		yamlBytes, err := os.ReadFile(tt.path)
+
+		if err != nil {
+			t.Fatalf("could not read %s: %v", tt.path, err)
+		}
+
+		file, err := types.ParseYAML(context.Background(), yamlBytes)
+
+		if err != nil {
+			t.Fatalf("could not parse %s: %v", tt.path, err)
+		}
+
+		for _, format := range []Format{FormatMermaid, FormatDOT} {
+			t.Run(tt.name+"."+string(format), func(t *testing.T) {
+				got, err := Render(&file, format)
+
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				goldenPath := filepath.Join("testdata", tt.name+"."+string(format)+".golden")
+
+				if *update {
+					if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				want, err := os.ReadFile(goldenPath)
+
+				if err != nil {
+					t.Fatalf("could not read golden file, run the tests with -update to create it: %v", err)
+				}
+
+				if got != string(want) {
+					t.Errorf("diagram does not match %s, run the tests with -update if the change is expected:\n%s", goldenPath, got)
+				}
+			})
+		}
+	}
+}
+
+func TestMermaidHeader(t *testing.T) {
+	file := &types.WorkflowFile{
+		Name: "a\nb\r\nc",
+		Jobs: map[string]types.WorkflowJob{
+			"job": {Steps: []types.WorkflowStep{{ID: "one", ActionID: "a:b", Timeout: "1s"}}},
+		},
+	}
+
+	got, err := Mermaid(file)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(got, "\n")
+
+	if lines[1] != "  %% a b c" || lines[2] != "  subgraph j0[\"job: job\"]" {
+		t.Errorf("expected the name on a single comment line, got:\n%s", got)
+	}
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	file := &types.WorkflowFile{Name: "file"}
+
+	if _, err := Render(file, "svg"); err == nil || !strings.Contains(err.Error(), `unsupported diagram format "svg"`) {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package diagram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+// DOT renders a workflow file as a Graphviz DOT digraph. Each job is drawn as a cluster containing its steps.
+func DOT(file *types.WorkflowFile) (string, error) {
+	g, err := newGraph(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "digraph \"%s\" {\n", dotEscaper.Replace(g.name))
+	sb.WriteString("  rankdir=LR;\n")
+	// compound edges are needed to draw edges between clusters
+	sb.WriteString("  compound=true;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for _, trigger := range g.triggers {
+		fmt.Fprintf(&sb, "  %s [label=\"%s\", shape=ellipse];\n", trigger.id, dotLabel(trigger.lines))
+	}
+
+	for _, job := range g.jobs {
+		fmt.Fprintf(&sb, "  subgraph cluster_%s {\n", job.id)
+		fmt.Fprintf(&sb, "    label=\"%s\";\n", dotEscaper.Replace(job.label))
+
+		for _, n := range job.nodes {
+			fmt.Fprintf(&sb, "    %s [label=\"%s\"];\n", n.id, dotLabel(n.lines))
+		}
+
+		sb.WriteString("  }\n")
+	}
+
+	for _, e := range g.edges {
+		attrs := []string{}
+
+		if e.fromCluster != nil {
+			attrs = append(attrs, "ltail=cluster_"+e.fromCluster.id)
+		}
+
+		if e.toCluster != nil {
+			attrs = append(attrs, "lhead=cluster_"+e.toCluster.id)
+		}
+
+		if e.label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotEscaper.Replace(e.label)))
+		}
+
+		if e.dashed {
+			attrs = append(attrs, "style=dashed")
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, "  %s -> %s [%s];\n", e.from.id, e.to.id, strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&sb, "  %s -> %s;\n", e.from.id, e.to.id)
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String(), nil
+}
+
+func dotLabel(lines []string) string {
+	return dotEscaper.Replace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,90 @@
+package diagram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\r\n", "<br/>",
+	"\n", "<br/>",
+	"\r", "<br/>",
+)
+
+// mermaidCommentEscaper replaces line breaks in comments, which end at the end of the line.
+var mermaidCommentEscaper = strings.NewReplacer(
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// Mermaid renders a workflow file as a Mermaid flowchart.
+func Mermaid(file *types.WorkflowFile) (string, error) {
+	g, err := newGraph(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("flowchart LR\n")
+	fmt.Fprintf(&sb, "  %%%% %s\n", mermaidCommentEscaper.Replace(g.name))
+
+	for _, trigger := range g.triggers {
+		fmt.Fprintf(&sb, "  %s([\"%s\"])\n", trigger.id, mermaidLabel(trigger.lines))
+	}
+
+	for _, job := range g.jobs {
+		fmt.Fprintf(&sb, "  subgraph %s[\"%s\"]\n", job.id, mermaidEscaper.Replace(job.label))
+		sb.WriteString("    direction TB\n")
+
+		for _, n := range job.nodes {
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", n.id, mermaidLabel(n.lines))
+		}
+
+		sb.WriteString("  end\n")
+	}
+
+	for _, e := range g.edges {
+		from, to := e.from.id, e.to.id
+
+		if e.fromCluster != nil {
+			from = e.fromCluster.id
+		}
+
+		if e.toCluster != nil {
+			to = e.toCluster.id
+		}
+
+		arrow := "-->"
+
+		if e.dashed {
+			arrow = "-.->"
+		}
+
+		if e.label != "" {
+			fmt.Fprintf(&sb, "  %s %s|\"%s\"| %s\n", from, arrow, mermaidEscaper.Replace(e.label), to)
+		} else {
+			fmt.Fprintf(&sb, "  %s %s %s\n", from, arrow, to)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func mermaidLabel(lines []string) string {
+	escaped := make([]string, len(lines))
+
+	for i, line := range lines {
+		escaped[i] = mermaidEscaper.Replace(line)
+	}
+
+	return strings.Join(escaped, "<br/>")
+}
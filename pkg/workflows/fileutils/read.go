@@ -17,7 +17,7 @@ func ReadHatchetYAMLFileBytes(filepath string) (*types.WorkflowFile, error) {
 		return nil, err
 	}
 
-	workflowFile, err := types.ParseYAML(context.Background(), yamlFileBytes)
+	workflowFile, err := types.ParseYAML(context.Background(), yamlFileBytes, types.WithFilePath(filepath))
 
 	if err != nil {
 		return nil, err
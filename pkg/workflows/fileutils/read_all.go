@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"io/fs"
 	"io/ioutil"
+	"log"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
+
 	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
 )
 
+// DefaultLoader reads all workflow files in the .hatchet folder in the current directory. Files which cannot be
+// parsed or are invalid are logged and skipped, as by [ReadAllValidFilesInDir]. Use [StrictLoader] to fail on
+// invalid files instead.
 func DefaultLoader() []*types.WorkflowFile {
 	workflowFiles, err := ReadAllValidFilesInDir("./.hatchet")
 
@@ -21,6 +27,62 @@ func DefaultLoader() []*types.WorkflowFile {
 	return workflowFiles
 }
 
+// StrictLoader reads all workflow files in the .hatchet folder in the current directory, as by
+// [ReadAllFilesInDir]. It panics if any file is invalid, so that a typo in a workflow file does not silently remove
+// the workflow.
+func StrictLoader() []*types.WorkflowFile {
+	workflowFiles, err := ReadAllFilesInDir("./.hatchet")
+
+	if err != nil {
+		panic(err)
+	}
+
+	return workflowFiles
+}
+
+// ReadAllFilesInDir reads and validates all workflow files in a directory, including subdirectories. Unknown
+// fields are not allowed, and names must be unique across files as checked by [types.ValidateFiles]. If any file
+// is invalid, it returns an error containing every problem in every file.
+func ReadAllFilesInDir(filedir string) ([]*types.WorkflowFile, error) {
+	files, err := readYAMLFiles(filedir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var workflowFiles []*types.WorkflowFile
+	var allErrs error
+
+	for _, file := range files {
+		workflowFile, err := types.ParseYAML(context.Background(), file.data, types.WithFilePath(file.path), types.WithStrictFields())
+
+		if err != nil {
+			allErrs = multierror.Append(allErrs, err)
+			continue
+		}
+
+		if err := workflowFile.Validate(); err != nil {
+			allErrs = multierror.Append(allErrs, err)
+			continue
+		}
+
+		workflowFiles = append(workflowFiles, &workflowFile)
+	}
+
+	if allErrs != nil {
+		return nil, allErrs
+	}
+
+	if err := types.ValidateFiles(workflowFiles); err != nil {
+		return nil, err
+	}
+
+	return workflowFiles, nil
+}
+
+// ReadAllValidFilesInDir reads all workflow files in a directory, including subdirectories, and skips files which
+// cannot be parsed or are invalid. Each skipped file is logged with its problems. Use [ReadAllFilesInDir] to return
+// an error for invalid files instead.
 func ReadAllValidFilesInDir(filedir string) ([]*types.WorkflowFile, error) {
 	files, err := readYAMLFiles(filedir)
 
@@ -31,9 +93,14 @@ func ReadAllValidFilesInDir(filedir string) ([]*types.WorkflowFile, error) {
 	var workflowFiles []*types.WorkflowFile
 
 	for _, file := range files {
-		workflowFile, err := types.ParseYAML(context.Background(), file)
+		workflowFile, err := types.ParseYAML(context.Background(), file.data, types.WithFilePath(file.path))
+
+		if err == nil {
+			err = workflowFile.Validate()
+		}
 
 		if err != nil {
+			log.Printf("skipping invalid workflow file %s: %v", file.path, err)
 			continue
 		}
 
@@ -43,9 +110,14 @@ func ReadAllValidFilesInDir(filedir string) ([]*types.WorkflowFile, error) {
 	return workflowFiles, nil
 }
 
+type yamlFile struct {
+	path string
+	data []byte
+}
+
 // readYAMLFiles reads all .yaml files in a given directory, including subdirectories.
-func readYAMLFiles(rootDir string) ([][]byte, error) {
-	yamlFiles := make([][]byte, 0)
+func readYAMLFiles(rootDir string) ([]yamlFile, error) {
+	yamlFiles := make([]yamlFile, 0)
 
 	// Walk the directory tree
 	err := filepath.WalkDir(rootDir, func(path string, info fs.DirEntry, err error) error {
@@ -61,7 +133,7 @@ func readYAMLFiles(rootDir string) ([][]byte, error) {
 				return fmt.Errorf("error reading file %s: %v", path, err)
 			}
 
-			yamlFiles = append(yamlFiles, data)
+			yamlFiles = append(yamlFiles, yamlFile{path, data})
 		}
 
 		return nil
@@ -0,0 +1,174 @@
+package fileutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validFile = `
+name: valid
+jobs:
+  greet:
+    steps:
+      - actionId: slack:send-message
+        timeout: 10s
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestReadAllValidFilesInDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"valid.yaml":          validFile,
+		"nested/unparsed.yml": "name: [",
+		"invalid.yaml":        "name: invalid\njobs: {}\n",
+		"readme.md":           "not a workflow file",
+	})
+
+	var logs bytes.Buffer
+
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	files, err := ReadAllValidFilesInDir(dir)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(files) != 1 || files[0].Name != "valid" {
+		t.Fatalf("got %d files, want only the valid file", len(files))
+	}
+
+	for _, want := range []string{
+		"skipping invalid workflow file " + filepath.Join(dir, "invalid.yaml"),
+		"at least one job is required",
+		"skipping invalid workflow file " + filepath.Join(dir, "nested", "unparsed.yml"),
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("got logs %q, want them to contain %q", logs.String(), want)
+		}
+	}
+}
+
+func TestReadAllFilesInDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    int
+		wantErr []string
+	}{
+		{
+			name:  "valid files",
+			files: map[string]string{"valid.yaml": validFile},
+			want:  1,
+		},
+		{
+			name: "unknown fields and invalid files",
+			files: map[string]string{
+				"valid.yaml":   validFile,
+				"unknown.yaml": "name: unknown\nstepz: []\n",
+				"invalid.yaml": "name: invalid\njobs: {}\n",
+			},
+			wantErr: []string{"unknown field stepz", "at least one job is required"},
+		},
+		{
+			name: "duplicate file names",
+			files: map[string]string{
+				"a.yaml": validFile,
+				"b.yaml": validFile,
+			},
+			wantErr: []string{"duplicate workflow file name valid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ReadAllFilesInDir(writeFiles(t, tt.files))
+
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if len(files) != tt.want {
+					t.Fatalf("got %d files, want %d", len(files), tt.want)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got error %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaders(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".hatchet/valid.yaml":   validFile,
+		".hatchet/invalid.yaml": "name: invalid\njobs: {}\n",
+	})
+
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	// the default loader skips invalid files
+	if files := DefaultLoader(); len(files) != 1 || files[0].Name != "valid" {
+		t.Errorf("got %d files from the default loader, want only the valid file", len(files))
+	}
+
+	defer func() {
+		r := recover()
+
+		if r == nil || !strings.Contains(fmt.Sprint(r), "at least one job is required") {
+			t.Errorf("expected the strict loader to panic for the invalid file, got %v", r)
+		}
+	}()
+
+	StrictLoader()
+}
@@ -0,0 +1,462 @@
+// Package schema generates the JSON Schema for workflow files, so that editors can autocomplete and validate
+// files in the .hatchet folder.
+package schema
+
+//go:generate go run ../../../hack/schemagen -out ../../../schemas/workflow-file.v1.json
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/integrations"
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+// Version is the version of the workflow file format described by the schema. It changes when the format changes
+// in a way which is not backwards compatible.
+const Version = "v1"
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches the durations accepted by time.ParseDuration, like 30s, 5m or 1h30m.
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// windowPattern matches the time of day windows of random schedules, like 09:00-17:00.
+const windowPattern = `^([01]?[0-9]|2[0-3]):[0-5][0-9]-([01]?[0-9]|2[0-3]):[0-5][0-9]$`
+
+// typedExpressionPattern matches `${{ expr }}` values, which render to the type of the expression's result.
+const typedExpressionPattern = `^\s*\$\{\{[\s\S]*\}\}\s*$`
+
+type generateOpts struct {
+	integrations []integrations.Integration
+}
+
+type GenerateOptFunc func(*generateOpts)
+
+// WithIntegrations includes the actions of the integrations in the schema. Integrations which implement
+// [integrations.ActionSchemaProvider] also describe the `with` input of each action.
+func WithIntegrations(ints ...integrations.Integration) GenerateOptFunc {
+	return func(opts *generateOpts) {
+		opts.integrations = append(opts.integrations, ints...)
+	}
+}
+
+// Generate returns the JSON Schema for workflow files, built from the workflow file types in the types package.
+func Generate(opts ...GenerateOptFunc) ([]byte, error) {
+	generateOpts := &generateOpts{}
+
+	for _, opt := range opts {
+		opt(generateOpts)
+	}
+
+	g := &generator{
+		definitions: map[string]map[string]interface{}{},
+	}
+
+	g.schemaFor(reflect.TypeOf(types.WorkflowFile{}))
+
+	// the workflow file is the root of the schema rather than a definition, as keywords next to a root $ref
+	// are ignored
+	root := g.definitions["WorkflowFile"]
+	delete(g.definitions, "WorkflowFile")
+
+	actionSchemas, err := getActionSchemas(generateOpts.integrations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actionSchemas) > 0 {
+		addActionSchemas(g.definitions["WorkflowStep"], actionSchemas)
+	}
+
+	res := map[string]interface{}{
+		"$schema":     draft,
+		"title":       fmt.Sprintf("Hatchet workflow file (%s)", Version),
+		"definitions": g.definitions,
+	}
+
+	for key, val := range root {
+		res[key] = val
+	}
+
+	resBytes, err := json.MarshalIndent(res, "", "  ")
+
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling schema: %w", err)
+	}
+
+	return append(resBytes, '\n'), nil
+}
+
+// typeOverrides replaces the schema generated for a type.
+var typeOverrides = map[reflect.Type]func() map[string]interface{}{
+	reflect.TypeOf(types.IDReusePolicy("")): func() map[string]interface{} {
+		return map[string]interface{}{
+			"type": "string",
+			"enum": types.IDReusePolicies,
+		}
+	},
+	// events are either an event name, or an object with a name and a filter
+	reflect.TypeOf(types.WorkflowEvent{}): func() map[string]interface{} {
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{
+					"type": "string",
+				},
+				map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []string{"name"},
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{"type": "string"},
+						"if":   map[string]interface{}{"type": "string"},
+					},
+				},
+			},
+		}
+	},
+	reflect.TypeOf(types.OverlapPolicy("")): func() map[string]interface{} {
+		return map[string]interface{}{
+			"type": "string",
+			"enum": types.OverlapPolicies,
+		}
+	},
+	reflect.TypeOf(types.InputType("")): func() map[string]interface{} {
+		return map[string]interface{}{
+			"type": "string",
+			"enum": types.InputTypes,
+		}
+	},
+}
+
+// fieldOverrides replaces the schema generated for a struct field, keyed by type and field name.
+var fieldOverrides = map[string]func() map[string]interface{}{
+	"WorkflowOnCron.Schedule": func() map[string]interface{} {
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{
+					"enum": types.RandomScheduleOpts,
+				},
+				map[string]interface{}{
+					"type": "string",
+				},
+			},
+		}
+	},
+	"WorkflowOnCron.Jitter": durationSchema,
+	"WorkflowOnCron.Window": func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":    "string",
+			"pattern": windowPattern,
+		}
+	},
+	"WorkflowOnCron.Start":            timestampSchema,
+	"WorkflowOnCron.End":              timestampSchema,
+	"WorkflowJob.Timeout":             durationSchema,
+	"WorkflowStep.Timeout":            durationSchema,
+	"WorkflowRetries.InitialInterval": durationSchema,
+	"WorkflowRetries.MaxInterval":     durationSchema,
+	"WorkflowStep.ActionID":           actionIDSchema,
+	"WorkflowRetries.MaxAttempts":     nonNegativeIntegerSchema,
+	"WorkflowForEach.MaxConcurrency":  nonNegativeIntegerSchema,
+	"WorkflowRetries.BackoffCoefficient": func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":    "number",
+			"minimum": 1,
+		}
+	},
+}
+
+// typeExtensions adds keywords to the schema generated for a struct, like required fields.
+var typeExtensions = map[string]map[string]interface{}{
+	"WorkflowFile": {
+		"required": []string{"name", "jobs"},
+	},
+	"WorkflowJob": {
+		"required": []string{"steps"},
+	},
+	"WorkflowStep": {
+		// a step either runs an action or is a group of parallel steps
+		"anyOf": []interface{}{
+			map[string]interface{}{"required": []string{"actionId"}},
+			map[string]interface{}{"required": []string{"parallel"}},
+		},
+	},
+	"WorkflowForEach": {
+		"required": []string{"items"},
+	},
+}
+
+func durationSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "string",
+		"pattern": durationPattern,
+	}
+}
+
+func timestampSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":   "string",
+		"format": "date-time",
+	}
+}
+
+func actionIDSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "string",
+		"pattern": "^[^:]+:.+$",
+	}
+}
+
+func nonNegativeIntegerSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "integer",
+		"minimum": 0,
+	}
+}
+
+type generator struct {
+	definitions map[string]map[string]interface{}
+}
+
+func (g *generator) schemaFor(t reflect.Type) map[string]interface{} {
+	if override, ok := typeOverrides[t]; ok {
+		return override()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.schemaFor(t.Elem()),
+		}
+	case reflect.Map:
+		res := map[string]interface{}{"type": "object"}
+
+		// maps of arbitrary values, like step inputs, allow any properties
+		if t.Elem().Kind() != reflect.Interface {
+			res["additionalProperties"] = g.schemaFor(t.Elem())
+		}
+
+		return res
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structRef adds the struct to the schema definitions, and returns a reference to it. Structs are referenced
+// so that recursive types like steps with compensations can be described.
+func (g *generator) structRef(t reflect.Type) map[string]interface{} {
+	ref := map[string]interface{}{
+		"$ref": "#/definitions/" + t.Name(),
+	}
+
+	if _, exists := g.definitions[t.Name()]; exists {
+		return ref
+	}
+
+	def := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+	}
+
+	// the definition is added before its fields are generated, so that fields of the same type reference it
+	g.definitions[t.Name()] = def
+
+	properties := map[string]interface{}{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		if override, ok := fieldOverrides[t.Name()+"."+field.Name]; ok {
+			properties[name] = override()
+		} else {
+			properties[name] = g.schemaFor(field.Type)
+		}
+	}
+
+	def["properties"] = properties
+
+	for key, val := range typeExtensions[t.Name()] {
+		def[key] = val
+	}
+
+	return ref
+}
+
+func getActionSchemas(ints []integrations.Integration) (map[string]map[string]interface{}, error) {
+	res := map[string]map[string]interface{}{}
+
+	for _, integration := range ints {
+		var schemas map[string]map[string]interface{}
+
+		if provider, ok := integration.(integrations.ActionSchemaProvider); ok {
+			schemas = provider.ActionSchemas()
+		}
+
+		for _, verb := range integration.Actions() {
+			actionID := types.Action{
+				IntegrationID: integration.GetId(),
+				Verb:          verb,
+			}.IntegrationVerbString()
+
+			if _, exists := res[actionID]; exists {
+				return nil, fmt.Errorf("action %s is registered by more than one integration", actionID)
+			}
+
+			// actions without a schema accept any input
+			res[actionID] = schemas[verb]
+		}
+	}
+
+	return res, nil
+}
+
+// addActionSchemas restricts step action IDs to the actions of the integrations, and validates the `with` input
+// of each step against the schema of its action.
+func addActionSchemas(stepDef map[string]interface{}, actionSchemas map[string]map[string]interface{}) {
+	actionIDs := make([]string, 0, len(actionSchemas))
+
+	for actionID := range actionSchemas {
+		actionIDs = append(actionIDs, actionID)
+	}
+
+	sort.Strings(actionIDs)
+
+	properties := stepDef["properties"].(map[string]interface{})
+
+	properties["actionId"] = map[string]interface{}{
+		"type": "string",
+		"enum": actionIDs,
+	}
+
+	conditions := []interface{}{}
+
+	for _, actionID := range actionIDs {
+		if actionSchemas[actionID] == nil {
+			continue
+		}
+
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"required": []string{"actionId"},
+				"properties": map[string]interface{}{
+					"actionId": map[string]interface{}{"const": actionID},
+				},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"with": allowTypedExpressions(actionSchemas[actionID], false),
+				},
+			},
+		})
+	}
+
+	if len(conditions) > 0 {
+		stepDef["allOf"] = conditions
+	}
+}
+
+// allowTypedExpressions returns a copy of the schema of an action input in which every nested value also accepts a
+// `${{ expr }}` string, so that a list or number can be set from the output of an earlier step. If wrap is true,
+// the value itself accepts the expression too.
+func allowTypedExpressions(schema map[string]interface{}, wrap bool) map[string]interface{} {
+	res := map[string]interface{}{}
+
+	for key, val := range schema {
+		res[key] = val
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		resProperties := map[string]interface{}{}
+
+		for name, property := range properties {
+			if propertySchema, ok := property.(map[string]interface{}); ok {
+				resProperties[name] = allowTypedExpressions(propertySchema, true)
+			} else {
+				resProperties[name] = property
+			}
+		}
+
+		res["properties"] = resProperties
+	}
+
+	for _, key := range []string{"items", "additionalProperties"} {
+		if child, ok := schema[key].(map[string]interface{}); ok {
+			res[key] = allowTypedExpressions(child, true)
+		}
+	}
+
+	if !wrap || acceptsAnyString(schema) {
+		return res
+	}
+
+	return map[string]interface{}{
+		"anyOf": []interface{}{
+			res,
+			map[string]interface{}{
+				"type":    "string",
+				"pattern": typedExpressionPattern,
+			},
+		},
+	}
+}
+
+// acceptsAnyString returns whether the schema accepts every string, and so accepts typed expressions already.
+func acceptsAnyString(schema map[string]interface{}) bool {
+	for _, keyword := range []string{"enum", "const", "pattern", "format", "minLength", "maxLength"} {
+		if _, exists := schema[keyword]; exists {
+			return false
+		}
+	}
+
+	switch t := schema["type"].(type) {
+	case string:
+		return t == "string"
+	case []string:
+		for _, val := range t {
+			if val == "string" {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, val := range t {
+			if val == "string" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
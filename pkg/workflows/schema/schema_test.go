@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hatchet-dev/hatchet-workflows/pkg/workflows/types"
+)
+
+func TestGenerateMatchesSchemaFile(t *testing.T) {
+	schemaBytes, err := Generate()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	existing, err := os.ReadFile("../../../schemas/workflow-file." + Version + ".json")
+
+	if err != nil {
+		t.Fatalf("could not read schema: %v", err)
+	}
+
+	if !bytes.Equal(existing, schemaBytes) {
+		t.Fatal("schemas/workflow-file." + Version + ".json is out of date, run `go generate ./pkg/workflows/schema`")
+	}
+}
+
+type testIntegration struct{}
+
+func (testIntegration) GetId() string {
+	return "test"
+}
+
+func (testIntegration) Actions() []string {
+	return []string{"invite", "ping"}
+}
+
+func (testIntegration) PerformAction(types.Action, map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+func (testIntegration) ActionSchemas() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"invite": {
+			"type":     "object",
+			"required": []string{"userIds"},
+			"properties": map[string]interface{}{
+				"userIds": map[string]interface{}{
+					"type":  "array",
+					"items": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateWithIntegrations(t *testing.T) {
+	schemaBytes, err := Generate(WithIntegrations(testIntegration{}))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var schema struct {
+		Definitions map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			AllOf      []struct {
+				Then struct {
+					Properties struct {
+						With struct {
+							Properties map[string]interface{} `json:"properties"`
+						} `json:"with"`
+					} `json:"properties"`
+				} `json:"then"`
+			} `json:"allOf"`
+		} `json:"definitions"`
+	}
+
+	if err := json.Unmarshal(schemaBytes, &schema); err != nil {
+		t.Fatalf("could not unmarshal schema: %v", err)
+	}
+
+	step := schema.Definitions["WorkflowStep"]
+
+	var actionID struct {
+		Enum []string `json:"enum"`
+	}
+
+	if err := json.Unmarshal(step.Properties["actionId"], &actionID); err != nil {
+		t.Fatalf("could not unmarshal actionId: %v", err)
+	}
+
+	if want := []string{"test:invite", "test:ping"}; !reflect.DeepEqual(actionID.Enum, want) {
+		t.Errorf("got action ids %v, want %v", actionID.Enum, want)
+	}
+
+	// actions without a schema accept any input, so only invite is validated
+	if len(step.AllOf) != 1 {
+		t.Fatalf("got %d action schemas, want 1", len(step.AllOf))
+	}
+
+	want := map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			map[string]interface{}{
+				"type":    "string",
+				"pattern": typedExpressionPattern,
+			},
+		},
+	}
+
+	if got := step.AllOf[0].Then.Properties.With.Properties["userIds"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got userIds schema %v, want %v", got, want)
+	}
+}
+
+func TestAllowTypedExpressions(t *testing.T) {
+	typedExpression := map[string]interface{}{
+		"type":    "string",
+		"pattern": typedExpressionPattern,
+	}
+
+	anyOf := func(schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"anyOf": []interface{}{schema, typedExpression},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name:   "strings accept expressions already",
+			schema: map[string]interface{}{"type": "string"},
+			want:   map[string]interface{}{"type": "string"},
+		},
+		{
+			name:   "types including strings accept expressions already",
+			schema: map[string]interface{}{"type": []interface{}{"string", "null"}},
+			want:   map[string]interface{}{"type": []interface{}{"string", "null"}},
+		},
+		{
+			name:   "enums",
+			schema: map[string]interface{}{"type": "string", "enum": []string{"a"}},
+			want:   anyOf(map[string]interface{}{"type": "string", "enum": []string{"a"}}),
+		},
+		{
+			name:   "numbers",
+			schema: map[string]interface{}{"type": "integer", "minimum": 1},
+			want:   anyOf(map[string]interface{}{"type": "integer", "minimum": 1}),
+		},
+		{
+			name: "nested objects and lists",
+			schema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"count": map[string]interface{}{"type": "number"},
+				},
+				"additionalProperties": map[string]interface{}{
+					"type":  "array",
+					"items": map[string]interface{}{"type": "boolean"},
+				},
+			},
+			want: anyOf(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"count": anyOf(map[string]interface{}{"type": "number"}),
+				},
+				"additionalProperties": anyOf(map[string]interface{}{
+					"type":  "array",
+					"items": anyOf(map[string]interface{}{"type": "boolean"}),
+				}),
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowTypedExpressions(tt.schema, true); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedExpressionPattern(t *testing.T) {
+	pattern := regexp.MustCompile(typedExpressionPattern)
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"${{ .steps.lookup.outputs.userIds }}", true},
+		{"  ${{ len .items }}\n", true},
+		{"${{ .a }} and {{ .b }}", true},
+		{"{{ .steps.lookup.outputs.userIds }}", false},
+		{"users: ${{ .userIds }}", false},
+		{"U123", false},
+	}
+
+	for _, tt := range tests {
+		if got := pattern.MatchString(tt.value); got != tt.want {
+			t.Errorf("got match %t for %q, want %t", got, tt.value, tt.want)
+		}
+	}
+}
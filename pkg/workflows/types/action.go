@@ -35,6 +35,10 @@ func ParseActionID(actionID string) (Action, error) {
 	parts := strings.Split(actionID, ":")
 	numParts := len(parts)
 
+	if numParts < 2 || numParts > 3 || parts[0] == "" || parts[1] == "" {
+		return Action{}, fmt.Errorf("invalid action id %q: must be in the form integration_id:verb", actionID)
+	}
+
 	integrationId := firstToLower(parts[0])
 	verb := strings.ToLower(parts[1])
 
@@ -1,44 +1,204 @@
 package types
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WorkflowTree is the dependency graph between the jobs of a workflow file. Root workflows are jobs which
+// do not need any other jobs.
 type WorkflowTree struct {
 	RootWorkflows []*WorkflowNode
+
+	nodes map[string]*WorkflowNode
 }
 
+// WorkflowNode is a single job in a [WorkflowTree]. Parents are the jobs this job needs, and children are the
+// jobs which need this job.
 type WorkflowNode struct {
 	Name     string
+	Parents  []*WorkflowNode
 	Children []*WorkflowNode
 }
 
 func NewWorkflowTree() *WorkflowTree {
 	return &WorkflowTree{
 		RootWorkflows: []*WorkflowNode{},
+		nodes:         map[string]*WorkflowNode{},
 	}
 }
 
 func newNode(name string) *WorkflowNode {
 	return &WorkflowNode{
 		Name:     name,
+		Parents:  []*WorkflowNode{},
 		Children: []*WorkflowNode{},
 	}
 }
 
 func (t *WorkflowTree) AddRootNode(name string) {
-	t.RootWorkflows = append(t.RootWorkflows, newNode(name))
+	node := newNode(name)
+
+	t.nodes[name] = node
+	t.RootWorkflows = append(t.RootWorkflows, node)
+}
+
+// GetNode returns the node for the given job name, or nil if the job does not exist in the tree.
+func (t *WorkflowTree) GetNode(name string) *WorkflowNode {
+	return t.nodes[name]
+}
+
+// HasDependencies returns true if any job in the tree needs another job.
+func (t *WorkflowTree) HasDependencies() bool {
+	return len(t.RootWorkflows) != len(t.nodes)
+}
+
+// Nodes returns all nodes in the tree in a deterministic topological order: every job appears after all
+// of the jobs that it needs, and ties are broken by job name.
+func (t *WorkflowTree) Nodes() []*WorkflowNode {
+	res := make([]*WorkflowNode, 0, len(t.nodes))
+	remaining := make(map[string]int, len(t.nodes))
+
+	for name, node := range t.nodes {
+		remaining[name] = len(node.Parents)
+	}
+
+	ready := make([]*WorkflowNode, len(t.RootWorkflows))
+	copy(ready, t.RootWorkflows)
+
+	for len(ready) > 0 {
+		sortNodes(ready)
+
+		node := ready[0]
+		ready = ready[1:]
+
+		res = append(res, node)
+
+		for _, child := range node.Children {
+			remaining[child.Name]--
+
+			if remaining[child.Name] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+
+	return res
 }
 
-func (t *WorkflowNode) AddNode(name string) error {
-	t.Children = append(t.Children, newNode(name))
+// AddNode adds a child to the node. The child must already exist in the tree.
+func (t *WorkflowNode) AddNode(child *WorkflowNode) error {
+	if child == nil {
+		return fmt.Errorf("cannot add nil child to job %s", t.Name)
+	}
+
+	t.Children = append(t.Children, child)
+	child.Parents = append(child.Parents, t)
 
 	return nil
 }
 
+// ParseWorkflowTreeFromFile builds the job dependency graph from the `needs` field of each job. It returns an
+// error if a job needs a job which does not exist, or if the dependencies contain a cycle.
 func ParseWorkflowTreeFromFile(file WorkflowFile) (*WorkflowTree, error) {
 	tree := NewWorkflowTree()
 
-	// add all jobs as root nodes. when dependencies are supported this will change.
-	for jobName := range file.Jobs {
-		tree.AddRootNode(jobName)
+	jobNames := file.ListJobNames()
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		tree.nodes[jobName] = newNode(jobName)
+	}
+
+	for _, jobName := range jobNames {
+		job := file.Jobs[jobName]
+		node := tree.nodes[jobName]
+
+		if len(job.Needs) == 0 {
+			tree.RootWorkflows = append(tree.RootWorkflows, node)
+			continue
+		}
+
+		seen := map[string]bool{}
+
+		for _, need := range job.Needs {
+			if seen[need] {
+				continue
+			}
+
+			seen[need] = true
+
+			parent, exists := tree.nodes[need]
+
+			if !exists {
+				return nil, fmt.Errorf("job %s needs unknown job %s", jobName, need)
+			}
+
+			if err := parent.AddNode(node); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if cycle := findCycle(tree, jobNames); cycle != nil {
+		return nil, fmt.Errorf("job dependencies contain a cycle: %s", strings.Join(cycle, " -> "))
 	}
 
 	return tree, nil
 }
+
+// findCycle returns the job names which form a cycle, or nil if the tree is acyclic.
+func findCycle(tree *WorkflowTree, jobNames []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(jobNames))
+	path := []string{}
+
+	var visit func(node *WorkflowNode) []string
+
+	visit = func(node *WorkflowNode) []string {
+		state[node.Name] = visiting
+		path = append(path, node.Name)
+
+		for _, child := range node.Children {
+			switch state[child.Name] {
+			case visiting:
+				for i, name := range path {
+					if name == child.Name {
+						return append(append([]string{}, path[i:]...), child.Name)
+					}
+				}
+			case unvisited:
+				if cycle := visit(child); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node.Name] = visited
+
+		return nil
+	}
+
+	for _, jobName := range jobNames {
+		if state[jobName] == unvisited {
+			if cycle := visit(tree.nodes[jobName]); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortNodes(nodes []*WorkflowNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+}
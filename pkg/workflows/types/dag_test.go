@@ -0,0 +1,117 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWorkflowTreeFromFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		needs     map[string][]string
+		wantOrder []string
+		wantRoots []string
+		wantErr   string
+	}{
+		{
+			name:      "independent jobs",
+			needs:     map[string][]string{"c": nil, "a": nil, "b": nil},
+			wantOrder: []string{"a", "b", "c"},
+			wantRoots: []string{"a", "b", "c"},
+		},
+		{
+			name: "diamond",
+			needs: map[string][]string{
+				"build":  nil,
+				"test":   {"build"},
+				"lint":   {"build"},
+				"deploy": {"test", "lint"},
+			},
+			wantOrder: []string{"build", "lint", "test", "deploy"},
+			wantRoots: []string{"build"},
+		},
+		{
+			name: "jobs are ordered after all of their needs",
+			needs: map[string][]string{
+				"a": {"z"},
+				"b": nil,
+				"z": {"b"},
+				"y": nil,
+			},
+			wantOrder: []string{"b", "y", "z", "a"},
+			wantRoots: []string{"b", "y"},
+		},
+		{
+			name:      "duplicate needs",
+			needs:     map[string][]string{"a": nil, "b": {"a", "a"}},
+			wantOrder: []string{"a", "b"},
+			wantRoots: []string{"a"},
+		},
+		{
+			name:    "unknown job",
+			needs:   map[string][]string{"a": {"missing"}},
+			wantErr: "job a needs unknown job missing",
+		},
+		{
+			name:    "self dependency",
+			needs:   map[string][]string{"a": {"a"}},
+			wantErr: "job dependencies contain a cycle: a -> a",
+		},
+		{
+			name: "cycle",
+			needs: map[string][]string{
+				"root": nil,
+				"a":    {"root", "c"},
+				"b":    {"a"},
+				"c":    {"b"},
+			},
+			wantErr: "job dependencies contain a cycle: a -> b -> c -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := WorkflowFile{Jobs: map[string]WorkflowJob{}}
+
+			for jobName, needs := range tt.needs {
+				file.Jobs[jobName] = WorkflowJob{Needs: needs}
+			}
+
+			tree, err := ParseWorkflowTreeFromFile(file)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := nodeNames(tree.Nodes()); !reflect.DeepEqual(got, tt.wantOrder) {
+				t.Errorf("got order %v, want %v", got, tt.wantOrder)
+			}
+
+			if got := nodeNames(tree.RootWorkflows); !reflect.DeepEqual(got, tt.wantRoots) {
+				t.Errorf("got roots %v, want %v", got, tt.wantRoots)
+			}
+
+			if got, want := tree.HasDependencies(), len(tt.wantRoots) != len(tt.wantOrder); got != want {
+				t.Errorf("got HasDependencies %t, want %t", got, want)
+			}
+		})
+	}
+}
+
+func nodeNames(nodes []*WorkflowNode) []string {
+	res := []string{}
+
+	for _, node := range nodes {
+		res = append(res, node.Name)
+	}
+
+	return res
+}
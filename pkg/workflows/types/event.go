@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYAML decodes an event from either an event name, or an object with the fields of the event.
+func (e *WorkflowEvent) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*e = WorkflowEvent{}
+
+		return node.Decode(&e.Name)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return &yaml.TypeError{
+			Errors: []string{fmt.Sprintf("line %d: an event must be an event name or an object with a name", node.Line)},
+		}
+	}
+
+	res := WorkflowEvent{}
+	unknownFields := []string{}
+
+	// unknown fields are always rejected, as the decoder does not pass its own settings to custom unmarshalers
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i], node.Content[i+1]
+
+		var err error
+
+		switch key.Value {
+		case "name":
+			err = val.Decode(&res.Name)
+		case "if":
+			err = val.Decode(&res.If)
+		default:
+			unknownFields = append(unknownFields, fmt.Sprintf("line %d: field %s not found in type types.WorkflowEvent", key.Line, key.Value))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(unknownFields) > 0 {
+		return &yaml.TypeError{Errors: unknownFields}
+	}
+
+	*e = res
+
+	return nil
+}
+
+// Matches returns true if the event name matches the name of the event, which may contain wildcards. Filters on
+// the event data are not evaluated.
+func (e WorkflowEvent) Matches(eventName string) bool {
+	matched, err := path.Match(e.Name, eventName)
+
+	return err == nil && matched
+}
+
+// String returns the name of the event, followed by its filter if it has one.
+func (e WorkflowEvent) String() string {
+	if e.If == "" {
+		return e.Name
+	}
+
+	return fmt.Sprintf("%s (if %s)", e.Name, e.If)
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWorkflowEventMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		event   string
+		want    bool
+	}{
+		{"user:create", "user:create", true},
+		{"user:create", "user:delete", false},
+		{"user:*", "user:create", true},
+		{"user:*", "user:", true},
+		{"user:*", "users:create", false},
+		{"*:create", "order:create", true},
+		{"order:?", "order:1", true},
+		{"order:?", "order:10", false},
+		{"order:[0-9]*", "order:10", true},
+		// invalid patterns never match
+		{"order:[", "order:[", false},
+	}
+
+	for _, tt := range tests {
+		if got := (WorkflowEvent{Name: tt.pattern}).Matches(tt.event); got != tt.want {
+			t.Errorf("got %t for %s matching %s, want %t", got, tt.pattern, tt.event, tt.want)
+		}
+	}
+}
+
+func TestWorkflowEventUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []WorkflowEvent
+		wantErr string
+	}{
+		{
+			name: "event names and objects",
+			yaml: "- user:create\n- name: order:created\n  if: gt .total 1000\n",
+			want: []WorkflowEvent{
+				{Name: "user:create"},
+				{Name: "order:created", If: "gt .total 1000"},
+			},
+		},
+		{
+			name:    "unknown fields",
+			yaml:    "- name: order:created\n  filter: gt .total 1000\n",
+			wantErr: "field filter not found",
+		},
+		{
+			name:    "lists",
+			yaml:    "- [user:create]\n",
+			wantErr: "an event must be an event name or an object with a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []WorkflowEvent
+
+			err := yaml.Unmarshal([]byte(tt.yaml), &got)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
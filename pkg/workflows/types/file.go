@@ -1,10 +1,16 @@
 package types
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
+	"time"
 
-	"gopkg.in/yaml.v2"
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
 )
 
 type WorkflowFile struct {
@@ -12,7 +18,47 @@ type WorkflowFile struct {
 
 	On WorkflowOn `yaml:"on"`
 
+	// Inputs declares the fields of the trigger input, keyed by field name. The dispatcher validates the input of
+	// every run against them and applies their defaults before any workflow starts.
+	Inputs map[string]WorkflowInput `yaml:"inputs,omitempty"`
+
+	// Env is a map of constants available to every job as `.env`. Jobs and steps can override its values.
+	Env map[string]interface{} `yaml:"env,omitempty"`
+
 	Jobs map[string]WorkflowJob `yaml:"jobs"`
+
+	// Outputs are the outputs of a run of the file, rendered from the results of its jobs under `.jobs`, like
+	// `{{ .jobs.setup.outputs.userId }}`.
+	Outputs map[string]interface{} `yaml:"outputs,omitempty"`
+
+	source *source
+}
+
+// FilePath returns the path the workflow file was read from, or an empty string if the file was not read
+// from disk.
+func (w *WorkflowFile) FilePath() string {
+	if w.source == nil {
+		return ""
+	}
+
+	return w.source.filePath
+}
+
+// UsesWorkflowRun returns true if every job of the file runs within a single Temporal workflow, which starts each
+// job as a child workflow. This is the case when jobs depend on each other, or when the file has outputs which
+// combine the outputs of its jobs. Otherwise, each job runs as its own workflow.
+func (w *WorkflowFile) UsesWorkflowRun() bool {
+	if len(w.Outputs) > 0 {
+		return true
+	}
+
+	for _, job := range w.Jobs {
+		if len(job.Needs) > 0 {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (w *WorkflowFile) GetJobByName(name string) *WorkflowJob {
@@ -36,24 +82,98 @@ func (w *WorkflowFile) ListJobNames() []string {
 }
 
 type WorkflowOn struct {
-	Events []string       `yaml:"events"`
-	Cron   WorkflowOnCron `yaml:"cron"`
+	// Events are the events which trigger the workflow file. Each event is either an event name, which may contain
+	// wildcards like `user:*`, or an object with a name and a filter on the event data.
+	Events []WorkflowEvent `yaml:"events"`
+
+	// Cron is a single schedule which triggers the workflow file.
+	Cron WorkflowOnCron `yaml:"cron"`
+
+	// Schedules are additional schedules which trigger the workflow file, for example in several timezones. Each
+	// schedule must have a unique name.
+	Schedules []WorkflowOnCron `yaml:"schedules,omitempty"`
+
+	// IdempotencyKey is a template rendered against the event data, like `user-{{ .userId }}`. Runs of the same
+	// job with the same key share a workflow ID, so duplicate events are deduplicated. If empty, every event
+	// starts a new run.
+	IdempotencyKey string `yaml:"idempotencyKey,omitempty"`
+
+	// ID is a template for the Temporal workflow ID of each job run, like
+	// `{{ .workflow.file }}/{{ .workflow.job }}/{{ .tenantId }}-{{ .workflow.key }}`. It is rendered against the
+	// trigger input, with `.workflow.file`, `.workflow.job` and `.workflow.key` set to the file name, job name and
+	// run key, which is the rendered idempotency key or a new UUID. If empty, the ID is
+	// `<file name>/<job name>/<run key>`.
+	ID string `yaml:"id,omitempty"`
+
+	// IDReusePolicy controls whether a run can start when a run with the same workflow ID already exists. It
+	// defaults to reject_duplicate if an idempotency key is set, and allow_duplicate otherwise.
+	IDReusePolicy IDReusePolicy `yaml:"idReusePolicy,omitempty"`
 }
 
+// IDReusePolicy maps to the Temporal workflow ID reuse policies.
+type IDReusePolicy string
+
+const (
+	AllowDuplicate           IDReusePolicy = "allow_duplicate"
+	AllowDuplicateFailedOnly IDReusePolicy = "allow_duplicate_failed_only"
+	RejectDuplicate          IDReusePolicy = "reject_duplicate"
+	TerminateIfRunning       IDReusePolicy = "terminate_if_running"
+)
+
+// IDReusePolicies lists every valid [IDReusePolicy].
+var IDReusePolicies = []IDReusePolicy{AllowDuplicate, AllowDuplicateFailedOnly, RejectDuplicate, TerminateIfRunning}
+
 type RandomScheduleOpt string
 
 const (
 	Random15Min  RandomScheduleOpt = "random_15_min"
 	RandomHourly RandomScheduleOpt = "random_hourly"
 	RandomDaily  RandomScheduleOpt = "random_daily"
+	RandomWeekly RandomScheduleOpt = "random_weekly"
 )
 
+// RandomScheduleOpts lists every [RandomScheduleOpt] which can be used in place of a cron schedule.
+var RandomScheduleOpts = []RandomScheduleOpt{Random15Min, RandomHourly, RandomDaily, RandomWeekly}
+
+// WorkflowOnCron is a schedule which triggers a workflow file. It maps to a Temporal schedule.
 type WorkflowOnCron struct {
+	// Name identifies the schedule among the schedules of the file. It is required when a file has more than one
+	// schedule.
+	Name string `yaml:"name,omitempty"`
+
+	// Schedule is a cron expression, or a [RandomScheduleOpt].
 	Schedule string `yaml:"schedule"`
+
+	// Timezone is the IANA timezone the cron expression is evaluated in, like America/New_York. Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Window limits the time of day picked by random_daily and random_weekly schedules, like 09:00-17:00. Windows
+	// which end before they start cross midnight.
+	Window string `yaml:"window,omitempty"`
+
+	// Jitter delays each run by a random duration up to the jitter, like 5m.
+	Jitter string `yaml:"jitter,omitempty"`
+
+	// Start and End limit the schedule to a time range, as RFC 3339 timestamps like 2024-01-01T00:00:00Z.
+	Start string `yaml:"start,omitempty"`
+	End   string `yaml:"end,omitempty"`
+
+	// Input is the static trigger input of every scheduled run. It is validated against the inputs of the file.
+	Input map[string]interface{} `yaml:"input,omitempty"`
+
+	// Overlap controls what happens when a run is due while the previous run is still running. Defaults to skip.
+	Overlap OverlapPolicy `yaml:"overlap,omitempty"`
 }
 
+// WorkflowEvent is an event which triggers a workflow file.
 type WorkflowEvent struct {
+	// Name is the name of the event. It is a pattern like `user:*`, where `*` matches any sequence of characters
+	// other than `/`, `?` matches any single character and `[...]` matches a class of characters.
 	Name string `yaml:"name"`
+
+	// If is a condition evaluated against the event data, like `gt .total 1000`. If the condition is falsy, the
+	// event does not trigger the workflow file.
+	If string `yaml:"if,omitempty"`
 }
 
 type WorkflowJob struct {
@@ -61,7 +181,64 @@ type WorkflowJob struct {
 
 	Timeout string `yaml:"timeout"`
 
+	// Needs is a list of jobs in the same file which must succeed before this job starts.
+	Needs []string `yaml:"needs,omitempty"`
+
+	// Retries is the default retry policy for steps in this job which do not set their own.
+	Retries *WorkflowRetries `yaml:"retries,omitempty"`
+
+	// If is a condition which must be truthy for the job to run, evaluated against the trigger input and the
+	// results of the jobs it needs. If the condition is falsy, the job is skipped.
+	If string `yaml:"if,omitempty"`
+
+	// Env is merged into the file's env for the job's steps and condition, overriding values with the same key.
+	Env map[string]interface{} `yaml:"env,omitempty"`
+
 	Steps []WorkflowStep `yaml:"steps"`
+
+	// OnFailure is a list of steps which run sequentially when the job fails, after any step compensations.
+	OnFailure []WorkflowStep `yaml:"onFailure,omitempty"`
+
+	// Outputs are the outputs of the job, rendered from the outputs of its steps once they all succeed, like
+	// `{{ .steps.createUser.outputs.id }}`. Jobs which need this job can read them under `.needs.<job>.outputs`.
+	Outputs map[string]interface{} `yaml:"outputs,omitempty"`
+}
+
+// ListAllSteps returns every step which runs an action: the steps of the job (with parallel groups replaced by
+// their steps), followed by the compensation of each step and the failure handlers.
+func (j WorkflowJob) ListAllSteps() []WorkflowStep {
+	steps := flattenSteps(j.Steps)
+
+	res := make([]WorkflowStep, 0, len(steps)+len(j.OnFailure))
+
+	res = append(res, steps...)
+
+	for _, step := range steps {
+		if step.Compensate != nil {
+			res = append(res, *step.Compensate)
+		}
+	}
+
+	return append(res, j.OnFailure...)
+}
+
+func flattenSteps(steps []WorkflowStep) []WorkflowStep {
+	res := make([]WorkflowStep, 0, len(steps))
+
+	for _, step := range steps {
+		if step.IsParallel() {
+			res = append(res, step.Parallel...)
+		} else {
+			res = append(res, step)
+		}
+	}
+
+	return res
+}
+
+// GetTimeout returns the timeout for the entire job, or 0 if the job does not set a timeout.
+func (j WorkflowJob) GetTimeout() (time.Duration, error) {
+	return parseTimeout(j.Timeout)
 }
 
 type WorkflowStep struct {
@@ -69,20 +246,170 @@ type WorkflowStep struct {
 	ID       string                 `yaml:"id"`
 	ActionID string                 `yaml:"actionId"`
 	Timeout  string                 `yaml:"timeout"`
+	If       string                 `yaml:"if,omitempty"`
+	Retries  *WorkflowRetries       `yaml:"retries,omitempty"`
 	With     map[string]interface{} `yaml:"with,omitempty"`
+
+	// Env is merged into the job's env for this step, overriding values with the same key.
+	Env map[string]interface{} `yaml:"env,omitempty"`
+
+	// Compensate is a step which undoes this step. If a later step in the job fails, the compensations of all
+	// succeeded steps run in reverse order.
+	Compensate *WorkflowStep `yaml:"compensate,omitempty"`
+
+	// ForEach runs the step's action once for each item in a list.
+	ForEach *WorkflowForEach `yaml:"forEach,omitempty"`
+
+	// Parallel is a group of steps which run concurrently. A step with parallel steps does not run an action
+	// itself, and the next step starts once all of the parallel steps complete.
+	Parallel []WorkflowStep `yaml:"parallel,omitempty"`
+}
+
+// WorkflowForEach runs a step once per item in a list. The outputs of the step are a list containing the outputs
+// of each item, in the same order as the items.
+type WorkflowForEach struct {
+	// Items is a template pipeline which evaluates to a list, like `.userIds` or `.steps.lookup.outputs.users`.
+	// Each item is available to the step as `.forEach.item`, and its position as `.forEach.index`.
+	Items string `yaml:"items"`
+
+	// MaxConcurrency is the maximum number of items which run at the same time. 0 runs all items at once.
+	MaxConcurrency int `yaml:"maxConcurrency,omitempty"`
+}
+
+// IsParallel returns true if the step is a group of parallel steps.
+func (s WorkflowStep) IsParallel() bool {
+	return len(s.Parallel) > 0
+}
+
+// GetTimeout returns the timeout for a single attempt of the step, or 0 if the step does not set a timeout.
+func (s WorkflowStep) GetTimeout() (time.Duration, error) {
+	return parseTimeout(s.Timeout)
+}
+
+// ValidateTimeouts returns an error for each job or step in the file with a timeout which is not a valid duration.
+func (w *WorkflowFile) ValidateTimeouts() error {
+	var allErrs error
+
+	jobNames := w.ListJobNames()
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		job := w.Jobs[jobName]
+
+		if _, err := job.GetTimeout(); err != nil {
+			allErrs = multierror.Append(allErrs, fmt.Errorf("job %s: %w", jobName, err))
+		}
+
+		for _, step := range job.ListAllSteps() {
+			if _, err := step.GetTimeout(); err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("job %s, step %s: %w", jobName, step.ID, err))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateRetries returns an error for each job or step in the file with invalid retries.
+func (w *WorkflowFile) ValidateRetries() error {
+	var allErrs error
+
+	jobNames := w.ListJobNames()
+	sort.Strings(jobNames)
+
+	for _, jobName := range jobNames {
+		job := w.Jobs[jobName]
+
+		if job.Retries != nil {
+			if _, err := job.Retries.Parse(); err != nil {
+				allErrs = multierror.Append(allErrs, fmt.Errorf("job %s: retries: %w", jobName, err))
+			}
+		}
+
+		for _, step := range job.ListAllSteps() {
+			if step.Retries != nil {
+				if _, err := step.Retries.Parse(); err != nil {
+					allErrs = multierror.Append(allErrs, fmt.Errorf("job %s, step %s: retries: %w", jobName, step.ID, err))
+				}
+			}
+		}
+	}
+
+	return allErrs
 }
 
-func ParseYAML(ctx context.Context, yamlBytes []byte) (WorkflowFile, error) {
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+
+	res, err := time.ParseDuration(timeout)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: must be a duration like 30s, 5m or 1h", timeout)
+	}
+
+	if res <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be greater than 0", timeout)
+	}
+
+	return res, nil
+}
+
+type parseOpts struct {
+	filePath     string
+	strictFields bool
+}
+
+type ParseOptFunc func(*parseOpts)
+
+// WithFilePath sets the path the workflow file was read from, which is included in parsing and validation errors.
+func WithFilePath(filePath string) ParseOptFunc {
+	return func(opts *parseOpts) {
+		opts.filePath = filePath
+	}
+}
+
+// WithStrictFields returns an error for fields in the file which do not exist in the workflow file format, like
+// a misspelled `actionId`.
+func WithStrictFields() ParseOptFunc {
+	return func(opts *parseOpts) {
+		opts.strictFields = true
+	}
+}
+
+// ParseYAML parses a workflow file. Parsing errors are returned as [ValidationError]s with the line of the problem.
+// Call [WorkflowFile.Validate] to check the parsed file for problems which would prevent it from running.
+func ParseYAML(ctx context.Context, yamlBytes []byte, opts ...ParseOptFunc) (WorkflowFile, error) {
 	var workflowFile WorkflowFile
 
 	if yamlBytes == nil {
 		return workflowFile, fmt.Errorf("workflow yaml input is nil")
 	}
 
-	err := yaml.Unmarshal(yamlBytes, &workflowFile)
-	if err != nil {
-		return workflowFile, fmt.Errorf("error unmarshaling workflow yaml: %w", err)
+	parseOpts := &parseOpts{}
+
+	for _, opt := range opts {
+		opt(parseOpts)
+	}
+
+	var root yaml.Node
+
+	if err := yaml.Unmarshal(yamlBytes, &root); err != nil {
+		return workflowFile, toParseError(parseOpts.filePath, nil, err)
 	}
 
+	src := newSource(parseOpts.filePath, &root)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlBytes))
+	decoder.KnownFields(parseOpts.strictFields)
+
+	// an empty file decodes to an empty workflow file, which fails validation
+	if err := decoder.Decode(&workflowFile); err != nil && !errors.Is(err, io.EOF) {
+		return workflowFile, toParseError(parseOpts.filePath, src, err)
+	}
+
+	workflowFile.source = src
+
 	return workflowFile, nil
 }
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+		wantErr string
+	}{
+		{timeout: "", want: 0},
+		{timeout: "30s", want: 30 * time.Second},
+		{timeout: "5m", want: 5 * time.Minute},
+		{timeout: "1h30m", want: 90 * time.Minute},
+		{timeout: "250ms", want: 250 * time.Millisecond},
+		{timeout: "30", wantErr: "must be a duration like 30s, 5m or 1h"},
+		{timeout: "soon", wantErr: "must be a duration like 30s, 5m or 1h"},
+		{timeout: "0s", wantErr: "must be greater than 0"},
+		{timeout: "-1m", wantErr: "must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timeout, func(t *testing.T) {
+			// jobs and steps parse their timeouts in the same way
+			jobTimeout, jobErr := WorkflowJob{Timeout: tt.timeout}.GetTimeout()
+			stepTimeout, stepErr := WorkflowStep{Timeout: tt.timeout}.GetTimeout()
+
+			for _, got := range []struct {
+				timeout time.Duration
+				err     error
+			}{{jobTimeout, jobErr}, {stepTimeout, stepErr}} {
+				if tt.wantErr != "" {
+					if got.err == nil || !strings.Contains(got.err.Error(), tt.wantErr) {
+						t.Fatalf("expected error containing %q, got %v", tt.wantErr, got.err)
+					}
+
+					continue
+				}
+
+				if got.err != nil {
+					t.Fatalf("unexpected error: %v", got.err)
+				}
+
+				if got.timeout != tt.want {
+					t.Errorf("got timeout %v, want %v", got.timeout, tt.want)
+				}
+			}
+		})
+	}
+}
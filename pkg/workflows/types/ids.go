@@ -0,0 +1,104 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+)
+
+// JobWorkflowID returns the default Temporal workflow ID of a job run. The run key is a generated UUID, or the
+// rendered idempotency key of the trigger.
+func JobWorkflowID(fileName, jobName, runKey string) string {
+	return fmt.Sprintf("%s/%s/%s", fileName, jobName, runKey)
+}
+
+// JobWorkflowType returns the name of the Temporal workflow type which runs a job. Jobs are registered under the
+// name of their workflow file, so that jobs with the same name in different files do not collide.
+func JobWorkflowType(fileName, jobName string) string {
+	return fmt.Sprintf("%s/%s", fileName, jobName)
+}
+
+// RenderJobWorkflowID returns the Temporal workflow ID of a job run. If the file sets `on.id`, the template is
+// rendered against the trigger input, the env of the file and a `workflow` object with the file name, job name
+// and run key. Otherwise, it returns [JobWorkflowID].
+func (w *WorkflowFile) RenderJobWorkflowID(data any, jobName, runKey string) (string, error) {
+	if w.On.ID == "" {
+		return JobWorkflowID(w.Name, jobName, runKey), nil
+	}
+
+	dataMap, err := datautils.ToJSONMap(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	dataMap = datautils.MergeMaps(dataMap, map[string]any{
+		"env": datautils.DeepCopyMap(w.Env),
+		"workflow": map[string]any{
+			"file": w.Name,
+			"job":  jobName,
+			"key":  runKey,
+		},
+	})
+
+	id, err := datautils.RenderTemplate(dataMap, "id", w.On.ID)
+
+	if err != nil {
+		return "", fmt.Errorf("job %s: invalid workflow id: %w", jobName, err)
+	}
+
+	if id == "" {
+		return "", fmt.Errorf("job %s: workflow id %q rendered an empty string", jobName, w.On.ID)
+	}
+
+	return id, nil
+}
+
+// WorkflowRunID returns the Temporal workflow ID of a run of a workflow file with job dependencies.
+func WorkflowRunID(fileName, runKey string) string {
+	return fmt.Sprintf("%s/%s", fileName, runKey)
+}
+
+// ScheduleID returns the Temporal schedule ID of a schedule of a workflow file, which is the file name followed
+// by the job name for files whose jobs are scheduled independently, and by the schedule name for named schedules.
+// Unnamed schedules have no suffix, so that files with a single schedule keep the ID of their schedule.
+func ScheduleID(fileName, jobName, scheduleName string) string {
+	res := fileName
+
+	if jobName != "" {
+		res += "/" + jobName
+	}
+
+	if scheduleName != "" {
+		res += "/" + scheduleName
+	}
+
+	return res
+}
+
+// Memo fields set on every Temporal workflow started by Hatchet, so that runs can be found by the workflow file,
+// job and event which started them. Schedules created by Hatchet are tagged with the same fields, so that
+// schedules which are no longer declared can be found.
+const (
+	MemoWorkflowFile = "hatchetWorkflowFile"
+	MemoJob          = "hatchetJob"
+	MemoEvent        = "hatchetEvent"
+)
+
+// RunMemo returns the memo for a Temporal workflow started by Hatchet. The job is empty for runs of a workflow
+// file with job dependencies, and the event is empty for scheduled runs.
+func RunMemo(fileName, jobName, event string) map[string]interface{} {
+	res := map[string]interface{}{
+		MemoWorkflowFile: fileName,
+	}
+
+	if jobName != "" {
+		res[MemoJob] = jobName
+	}
+
+	if event != "" {
+		res[MemoEvent] = event
+	}
+
+	return res
+}
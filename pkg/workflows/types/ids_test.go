@@ -0,0 +1,94 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderJobWorkflowID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		data    any
+		want    string
+		wantErr string
+	}{
+		{
+			name: "default",
+			data: map[string]any{"userId": "1"},
+			want: "post-user-sign-up/greet/key",
+		},
+		{
+			name: "template",
+			id:   "{{ .workflow.job }}/{{ .tenantId }}/{{ .env.REGION }}/{{ .workflow.key }}",
+			data: map[string]any{"tenantId": "acme"},
+			want: "greet/acme/eu/key",
+		},
+		{
+			name: "file name",
+			id:   "{{ .workflow.file }}-{{ .workflow.job }}",
+			data: nil,
+			want: "post-user-sign-up-greet",
+		},
+		{
+			name:    "missing field",
+			id:      "{{ .workflow.job }}/{{ .tenantId }}",
+			data:    map[string]any{},
+			wantErr: `no entry for key "tenantId"`,
+		},
+		{
+			name:    "empty",
+			id:      "{{ .tenantId }}",
+			data:    map[string]any{"tenantId": ""},
+			wantErr: "rendered an empty string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &WorkflowFile{
+				Name: "post-user-sign-up",
+				On:   WorkflowOn{ID: tt.id},
+				Env:  map[string]any{"REGION": "eu"},
+			}
+
+			got, err := file.RenderJobWorkflowID(tt.data, "greet", "key")
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleID(t *testing.T) {
+	tests := []struct {
+		fileName     string
+		jobName      string
+		scheduleName string
+		want         string
+	}{
+		{"reports", "", "", "reports"},
+		{"reports", "", "daily", "reports/daily"},
+		{"reports", "send", "", "reports/send"},
+		{"reports", "send", "daily", "reports/send/daily"},
+	}
+
+	for _, tt := range tests {
+		if got := ScheduleID(tt.fileName, tt.jobName, tt.scheduleName); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+)
+
+// WorkflowInput declares a field of the trigger input of a workflow file.
+type WorkflowInput struct {
+	Description string `yaml:"description,omitempty"`
+
+	// Type is the JSON type of the input. If empty, any value is accepted.
+	Type InputType `yaml:"type,omitempty"`
+
+	// Required inputs must be set and not null, unless they have a default.
+	Required bool `yaml:"required,omitempty"`
+
+	// Default is used when the input is not set or is null.
+	Default interface{} `yaml:"default,omitempty"`
+
+	// Enum lists the allowed values of the input.
+	Enum []interface{} `yaml:"enum,omitempty"`
+}
+
+// InputType is the JSON type of a [WorkflowInput].
+type InputType string
+
+const (
+	InputTypeString  InputType = "string"
+	InputTypeNumber  InputType = "number"
+	InputTypeInteger InputType = "integer"
+	InputTypeBoolean InputType = "boolean"
+	InputTypeObject  InputType = "object"
+	InputTypeArray   InputType = "array"
+)
+
+// InputTypes lists every valid [InputType].
+var InputTypes = []InputType{InputTypeString, InputTypeNumber, InputTypeInteger, InputTypeBoolean, InputTypeObject, InputTypeArray}
+
+// InputError is a problem with the trigger input of a run, like a missing required input or a value which is not
+// in the enum of the input.
+type InputError struct {
+	WorkflowFile string
+	Input        string
+	Message      string
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("workflow file %s: input %s: %s", e.WorkflowFile, e.Input, e.Message)
+}
+
+// ApplyInputs validates the trigger input of a run against the inputs of the workflow file, and returns the input
+// as a map with the defaults of unset inputs applied. Fields which are not declared are kept. It returns an
+// [InputError] for every invalid input, combined with multierror.
+func (w *WorkflowFile) ApplyInputs(data interface{}) (map[string]interface{}, error) {
+	res, err := datautils.ToJSONMap(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("workflow file %s: input must be an object: %w", w.Name, err)
+	}
+
+	// a nil input decodes to a nil map
+	if res == nil {
+		res = map[string]interface{}{}
+	}
+
+	var allErrs error
+
+	for _, name := range w.listInputNames() {
+		input := w.Inputs[name]
+
+		if val, exists := res[name]; exists && val != nil {
+			if msg := input.check(val); msg != "" {
+				allErrs = multierror.Append(allErrs, &InputError{WorkflowFile: w.Name, Input: name, Message: msg})
+			}
+
+			continue
+		}
+
+		if input.Default != nil {
+			def, err := toJSONValue(input.Default)
+
+			if err != nil {
+				return nil, fmt.Errorf("workflow file %s: input %s: invalid default: %w", w.Name, name, err)
+			}
+
+			res[name] = def
+		} else if input.Required {
+			allErrs = multierror.Append(allErrs, &InputError{WorkflowFile: w.Name, Input: name, Message: "is required"})
+		}
+	}
+
+	if allErrs != nil {
+		return nil, allErrs
+	}
+
+	return res, nil
+}
+
+func (w *WorkflowFile) listInputNames() []string {
+	res := make([]string, 0, len(w.Inputs))
+
+	for name := range w.Inputs {
+		res = append(res, name)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// check returns a message describing why the value is not valid for the input, or an empty string if it is valid.
+func (i WorkflowInput) check(val interface{}) string {
+	val, err := toJSONValue(val)
+
+	if err != nil {
+		return err.Error()
+	}
+
+	if i.Type != "" && !hasInputType(val, i.Type) {
+		return fmt.Sprintf("expected %s, got %s", i.Type, jsonTypeName(val))
+	}
+
+	if len(i.Enum) == 0 {
+		return ""
+	}
+
+	allowed := make([]string, 0, len(i.Enum))
+
+	for _, item := range i.Enum {
+		item, err := toJSONValue(item)
+
+		if err != nil {
+			return err.Error()
+		}
+
+		if reflect.DeepEqual(val, item) {
+			return ""
+		}
+
+		itemBytes, _ := json.Marshal(item)
+		allowed = append(allowed, string(itemBytes))
+	}
+
+	return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+func hasInputType(val interface{}, inputType InputType) bool {
+	switch inputType {
+	case InputTypeString:
+		_, ok := val.(string)
+		return ok
+	case InputTypeNumber:
+		_, ok := val.(float64)
+		return ok
+	case InputTypeInteger:
+		num, ok := val.(float64)
+		return ok && num == math.Trunc(num)
+	case InputTypeBoolean:
+		_, ok := val.(bool)
+		return ok
+	case InputTypeObject:
+		_, ok := val.(map[string]interface{})
+		return ok
+	case InputTypeArray:
+		_, ok := val.([]interface{})
+		return ok
+	default:
+		return false
+	}
+}
+
+func jsonTypeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
+
+// toJSONValue converts a value to the types returned by encoding/json, so that values from the trigger input and
+// from the workflow file can be compared.
+func toJSONValue(val interface{}) (interface{}, error) {
+	jsonBytes, err := json.Marshal(val)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res interface{}
+
+	if err := json.Unmarshal(jsonBytes, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
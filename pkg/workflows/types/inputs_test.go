@@ -0,0 +1,125 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyInputs(t *testing.T) {
+	file := &WorkflowFile{
+		Name: "sign-up",
+		Inputs: map[string]WorkflowInput{
+			"username": {Type: InputTypeString, Required: true},
+			"plan":     {Type: InputTypeString, Enum: []interface{}{"free", "pro"}, Default: "free"},
+			"seats":    {Type: InputTypeInteger, Default: 1},
+			"price":    {Type: InputTypeNumber},
+			"admin":    {Type: InputTypeBoolean},
+			"tags":     {Type: InputTypeArray},
+			"meta":     {Type: InputTypeObject},
+			"level":    {Enum: []interface{}{1, 2}},
+		},
+	}
+
+	type user struct {
+		Username string `json:"username"`
+		Seats    int    `json:"seats"`
+	}
+
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    map[string]interface{}
+		wantErr []string
+	}{
+		{
+			name: "defaults",
+			data: map[string]interface{}{"username": "bob"},
+			want: map[string]interface{}{"username": "bob", "plan": "free", "seats": float64(1)},
+		},
+		{
+			name: "null values use defaults",
+			data: map[string]interface{}{"username": "bob", "plan": nil},
+			want: map[string]interface{}{"username": "bob", "plan": "free", "seats": float64(1)},
+		},
+		{
+			name: "structs and undeclared fields",
+			data: struct {
+				user
+				Extra string `json:"extra"`
+			}{user{"bob", 3}, "kept"},
+			want: map[string]interface{}{"username": "bob", "plan": "free", "seats": float64(3), "extra": "kept"},
+		},
+		{
+			name: "values of every type",
+			data: map[string]interface{}{
+				"username": "bob",
+				"plan":     "pro",
+				"seats":    2.0,
+				"price":    9.5,
+				"admin":    true,
+				"tags":     []string{"a"},
+				"meta":     map[string]int{"a": 1},
+				"level":    2,
+			},
+			want: map[string]interface{}{
+				"username": "bob",
+				"plan":     "pro",
+				"seats":    float64(2),
+				"price":    9.5,
+				"admin":    true,
+				"tags":     []interface{}{"a"},
+				"meta":     map[string]interface{}{"a": float64(1)},
+				"level":    float64(2),
+			},
+		},
+		{
+			name:    "missing required input",
+			data:    nil,
+			wantErr: []string{"workflow file sign-up: input username: is required"},
+		},
+		{
+			name: "invalid values",
+			data: map[string]interface{}{
+				"username": 1,
+				"plan":     "enterprise",
+				"seats":    1.5,
+				"admin":    "yes",
+				"tags":     "a",
+				"meta":     []int{},
+				"level":    3,
+			},
+			wantErr: []string{
+				"input admin: expected boolean, got string",
+				"input level: must be one of 1, 2",
+				"input meta: expected object, got array",
+				`input plan: must be one of "free", "pro"`,
+				"input seats: expected integer, got number",
+				"input tags: expected array, got string",
+				"input username: expected string, got number",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := file.ApplyInputs(tt.data)
+
+			if len(tt.wantErr) > 0 {
+				checkProblems(t, problems(err), tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := file.ApplyInputs("not an object"); err == nil {
+		t.Error("expected an error for an input which is not an object")
+	}
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+)
+
+// Lint checks a valid workflow file for problems which do not prevent it from running but are likely mistakes,
+// like steps without a timeout or step IDs which are never referenced. It returns a [ValidationError] with Warning
+// set for every warning found, combined with multierror.
+func (w *WorkflowFile) Lint() error {
+	v := &validator{file: w}
+
+	jobNames := w.ListJobNames()
+	sort.Strings(jobNames)
+
+	// references to the steps of each job, from the job itself and from the jobs which need it
+	referencedSteps := map[string]map[string]bool{}
+
+	for _, jobName := range jobNames {
+		referencedSteps[jobName] = map[string]bool{}
+	}
+
+	// steps of other jobs are referenced by jobs under `.needs` and by the outputs of the file under `.jobs`
+	referenceJobStep := func(ref []string) {
+		if len(ref) >= 4 && ref[2] == "steps" && referencedSteps[ref[1]] != nil {
+			referencedSteps[ref[1]][ref[3]] = true
+		}
+	}
+
+	for _, jobName := range jobNames {
+		for _, ref := range jobReferences(w.Jobs[jobName]) {
+			switch {
+			case len(ref) >= 2 && ref[0] == "steps":
+				referencedSteps[jobName][ref[1]] = true
+			case ref[0] == "needs":
+				referenceJobStep(ref)
+			}
+		}
+	}
+
+	for _, ref := range templateReferences(w.Outputs) {
+		if ref[0] == "jobs" {
+			referenceJobStep(ref)
+		}
+	}
+
+	for _, jobName := range jobNames {
+		v.lintJob(jobName, w.Jobs[jobName], referencedSteps[jobName])
+	}
+
+	return v.errs
+}
+
+func (v *validator) lintJob(jobName string, job WorkflowJob, referencedSteps map[string]bool) {
+	path := fmt.Sprintf("jobs.%s", jobName)
+
+	for i, step := range job.Steps {
+		stepPath := fmt.Sprintf("%s.steps[%d]", path, i)
+
+		// the last step of a job is often kept for its result rather than referenced, like the steps of a group
+		// which ends the job
+		isLast := i == len(job.Steps)-1
+
+		if !step.IsParallel() {
+			v.lintStep(stepPath, step, referencedSteps, isLast)
+			continue
+		}
+
+		for j, groupStep := range step.Parallel {
+			v.lintStep(fmt.Sprintf("%s.parallel[%d]", stepPath, j), groupStep, referencedSteps, isLast)
+		}
+	}
+
+	for i, step := range job.OnFailure {
+		v.lintTimeout(fmt.Sprintf("%s.onFailure[%d]", path, i), step)
+	}
+}
+
+func (v *validator) lintStep(path string, step WorkflowStep, referencedSteps map[string]bool, isLast bool) {
+	v.lintTimeout(path, step)
+
+	if step.ID != "" && !referencedSteps[step.ID] && !isLast {
+		v.addWarning(path+".id", "step id %s is never referenced", step.ID)
+	}
+
+	if step.Compensate != nil {
+		v.lintTimeout(path+".compensate", *step.Compensate)
+	}
+}
+
+func (v *validator) lintTimeout(path string, step WorkflowStep) {
+	if step.Timeout == "" {
+		v.addWarning(path, "step has no timeout, so the default step timeout is used")
+	}
+}
+
+// jobReferences returns the field chains referenced in every template in the job. Templates which cannot be
+// parsed are skipped, as they are reported by [WorkflowFile.Validate].
+func jobReferences(job WorkflowJob) [][]string {
+	res := [][]string{}
+
+	if job.If != "" {
+		refs, _ := datautils.ExpressionReferences(job.If)
+		res = append(res, refs...)
+	}
+
+	for _, step := range job.ListAllSteps() {
+		if step.If != "" {
+			refs, _ := datautils.ExpressionReferences(step.If)
+			res = append(res, refs...)
+		}
+
+		if step.ForEach != nil && step.ForEach.Items != "" {
+			refs, _ := datautils.ExpressionReferences(step.ForEach.Items)
+			res = append(res, refs...)
+		}
+
+		res = append(res, templateReferences(step.With)...)
+	}
+
+	res = append(res, templateReferences(job.Outputs)...)
+
+	return res
+}
+
+func templateReferences(value interface{}) [][]string {
+	res := [][]string{}
+
+	switch val := value.(type) {
+	case string:
+		refs, _ := datautils.TemplateReferences(val)
+		res = append(res, refs...)
+	case map[string]interface{}:
+		for _, item := range val {
+			res = append(res, templateReferences(item)...)
+		}
+	case []interface{}:
+		for _, item := range val {
+			res = append(res, templateReferences(item)...)
+		}
+	}
+
+	return res
+}
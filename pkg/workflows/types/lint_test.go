@@ -0,0 +1,155 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "terminal step",
+			yaml: `
+name: lint
+jobs:
+  a:
+    steps:
+      - id: first
+        actionId: a:b
+        timeout: 1s
+      - id: second
+        actionId: a:b
+        timeout: 1s
+        with:
+          value: "{{ .steps.first.outputs.id }}"
+`,
+			want: []string{},
+		},
+		{
+			name: "terminal parallel group",
+			yaml: `
+name: lint
+jobs:
+  a:
+    steps:
+      - id: first
+        actionId: a:b
+        timeout: 1s
+      - parallel:
+          - id: left
+            actionId: a:b
+            timeout: 1s
+            with:
+              value: "{{ .steps.first.outputs.id }}"
+          - id: right
+            actionId: a:b
+            timeout: 1s
+`,
+			want: []string{},
+		},
+		{
+			name: "unreferenced step",
+			yaml: `
+name: lint
+jobs:
+  a:
+    steps:
+      - id: first
+        actionId: a:b
+        timeout: 1s
+      - id: second
+        actionId: a:b
+        timeout: 1s
+`,
+			want: []string{
+				"jobs.a.steps[0].id: step id first is never referenced",
+			},
+		},
+		{
+			name: "steps referenced by job outputs and other jobs",
+			yaml: `
+name: lint
+jobs:
+  a:
+    steps:
+      - id: lookup
+        actionId: a:b
+        timeout: 1s
+      - id: fetch
+        actionId: a:b
+        timeout: 1s
+      - id: last
+        actionId: a:b
+        timeout: 1s
+    outputs:
+      id: "{{ .steps.lookup.outputs.id }}"
+  b:
+    needs: [a]
+    steps:
+      - actionId: a:b
+        timeout: 1s
+        with:
+          value: "{{ .needs.a.steps.fetch.outputs.id }}"
+`,
+			want: []string{},
+		},
+		{
+			name: "missing timeouts",
+			yaml: `
+name: lint
+jobs:
+  a:
+    steps:
+      - actionId: a:b
+        compensate:
+          actionId: a:c
+    onFailure:
+      - actionId: a:d
+`,
+			want: []string{
+				"jobs.a.steps[0]: step has no timeout",
+				"jobs.a.steps[0].compensate: step has no timeout",
+				"jobs.a.onFailure[0]: step has no timeout",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseYAML(context.Background(), []byte(tt.yaml), WithStrictFields())
+
+			if err != nil {
+				t.Fatalf("could not parse file: %v", err)
+			}
+
+			if err := file.Validate(); err != nil {
+				t.Fatalf("file is not valid: %v", err)
+			}
+
+			err = file.Lint()
+
+			checkProblems(t, problems(err), tt.want)
+
+			var merr *multierror.Error
+
+			if errors.As(err, &merr) {
+				for _, problem := range merr.Errors {
+					var verr *ValidationError
+
+					if !errors.As(problem, &verr) || !verr.Warning {
+						t.Errorf("problem %q is not a warning", problem)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package types
+
+// RunStatus is the status of a completed job or step.
+type RunStatus string
+
+const (
+	RunStatusSucceeded   RunStatus = "succeeded"
+	RunStatusSkipped     RunStatus = "skipped"
+	RunStatusCompensated RunStatus = "compensated"
+)
+
+// JobResult is the result of a job run, returned by the Temporal workflow which backs the job.
+type JobResult struct {
+	Status RunStatus `json:"status"`
+
+	// Steps contains the status and outputs of each step, keyed by step id.
+	Steps map[string]interface{} `json:"steps"`
+
+	// Outputs contains the rendered outputs of the job, if the job succeeded.
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+}
+
+// RunResult is the result of a run of every job in a workflow file, returned by the Temporal workflow which runs
+// files with job dependencies or outputs.
+type RunResult struct {
+	// Jobs contains the result of each job, keyed by job name.
+	Jobs map[string]*JobResult `json:"jobs"`
+
+	// Outputs contains the rendered outputs of the workflow file.
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+}
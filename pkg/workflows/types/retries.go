@@ -0,0 +1,105 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultMaxAttempts is the maximum number of attempts of retries which do not set maxAttempts, so that a
+// retries block never retries a step forever.
+const DefaultMaxAttempts = 3
+
+// WorkflowRetries configures how a step is retried when it fails. It can be set on a step, or on a job as the
+// default for all of its steps.
+type WorkflowRetries struct {
+	// The maximum number of attempts, including the first attempt. Defaults to [DefaultMaxAttempts].
+	MaxAttempts int32 `yaml:"maxAttempts,omitempty"`
+
+	// The duration to wait before the first retry, like 1s. Defaults to 1s.
+	InitialInterval string `yaml:"initialInterval,omitempty"`
+
+	// The maximum duration to wait between retries. Defaults to 100x the initial interval.
+	MaxInterval string `yaml:"maxInterval,omitempty"`
+
+	// The multiplier applied to the wait duration after each retry. Defaults to 2.
+	BackoffCoefficient float64 `yaml:"backoffCoefficient,omitempty"`
+
+	// Error types which should not be retried. Integrations set the error type by returning an ActionError from
+	// the integrations package.
+	NonRetryableErrorTypes []string `yaml:"nonRetryableErrorTypes,omitempty"`
+}
+
+// RetryPolicy is a parsed [WorkflowRetries].
+type RetryPolicy struct {
+	MaxAttempts            int32
+	InitialInterval        time.Duration
+	MaxInterval            time.Duration
+	BackoffCoefficient     float64
+	NonRetryableErrorTypes []string
+}
+
+// Parse validates the retries and returns the parsed retry policy.
+func (r *WorkflowRetries) Parse() (*RetryPolicy, error) {
+	res := &RetryPolicy{
+		MaxAttempts:            r.MaxAttempts,
+		BackoffCoefficient:     r.BackoffCoefficient,
+		NonRetryableErrorTypes: r.NonRetryableErrorTypes,
+	}
+
+	if r.MaxAttempts < 0 {
+		return nil, fmt.Errorf("invalid maxAttempts %d: must not be negative", r.MaxAttempts)
+	}
+
+	// Temporal retries activities without a maximum number of attempts until the job times out
+	if res.MaxAttempts == 0 {
+		res.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if r.BackoffCoefficient != 0 && r.BackoffCoefficient < 1 {
+		return nil, fmt.Errorf("invalid backoffCoefficient %v: must be at least 1", r.BackoffCoefficient)
+	}
+
+	var err error
+
+	if res.InitialInterval, err = parseInterval("initialInterval", r.InitialInterval); err != nil {
+		return nil, err
+	}
+
+	if res.MaxInterval, err = parseInterval("maxInterval", r.MaxInterval); err != nil {
+		return nil, err
+	}
+
+	if res.InitialInterval != 0 && res.MaxInterval != 0 && res.MaxInterval < res.InitialInterval {
+		return nil, fmt.Errorf("invalid maxInterval %q: must not be less than initialInterval", r.MaxInterval)
+	}
+
+	return res, nil
+}
+
+// GetRetries returns the retries for the step, falling back to the retries of the job. It returns nil if neither
+// the step nor the job set retries.
+func (j WorkflowJob) GetRetries(step WorkflowStep) *WorkflowRetries {
+	if step.Retries != nil {
+		return step.Retries
+	}
+
+	return j.Retries
+}
+
+func parseInterval(field, interval string) (time.Duration, error) {
+	if interval == "" {
+		return 0, nil
+	}
+
+	res, err := time.ParseDuration(interval)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be a duration like 1s or 5m", field, interval)
+	}
+
+	if res <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be greater than 0", field, interval)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkflowRetriesParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries WorkflowRetries
+		want    RetryPolicy
+		wantErr string
+	}{
+		{
+			name:    "defaults to a finite number of attempts",
+			retries: WorkflowRetries{},
+			want:    RetryPolicy{MaxAttempts: DefaultMaxAttempts},
+		},
+		{
+			name: "parses every field",
+			retries: WorkflowRetries{
+				MaxAttempts:            5,
+				InitialInterval:        "1s",
+				MaxInterval:            "1m",
+				BackoffCoefficient:     1.5,
+				NonRetryableErrorTypes: []string{"InvalidInput"},
+			},
+			want: RetryPolicy{
+				MaxAttempts:            5,
+				InitialInterval:        time.Second,
+				MaxInterval:            time.Minute,
+				BackoffCoefficient:     1.5,
+				NonRetryableErrorTypes: []string{"InvalidInput"},
+			},
+		},
+		{
+			name:    "rejects negative attempts",
+			retries: WorkflowRetries{MaxAttempts: -1},
+			wantErr: "invalid maxAttempts -1",
+		},
+		{
+			name:    "rejects a backoff coefficient below 1",
+			retries: WorkflowRetries{BackoffCoefficient: 0.5},
+			wantErr: "invalid backoffCoefficient 0.5",
+		},
+		{
+			name:    "rejects invalid intervals",
+			retries: WorkflowRetries{InitialInterval: "soon"},
+			wantErr: `invalid initialInterval "soon"`,
+		},
+		{
+			name:    "rejects a max interval below the initial interval",
+			retries: WorkflowRetries{InitialInterval: "1m", MaxInterval: "1s"},
+			wantErr: `invalid maxInterval "1s"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.retries.Parse()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.MaxAttempts != tt.want.MaxAttempts || got.InitialInterval != tt.want.InitialInterval ||
+				got.MaxInterval != tt.want.MaxInterval || got.BackoffCoefficient != tt.want.BackoffCoefficient ||
+				strings.Join(got.NonRetryableErrorTypes, ",") != strings.Join(tt.want.NonRetryableErrorTypes, ",") {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverlapPolicy maps to the Temporal schedule overlap policies.
+type OverlapPolicy string
+
+const (
+	OverlapSkip           OverlapPolicy = "skip"
+	OverlapBufferOne      OverlapPolicy = "buffer_one"
+	OverlapBufferAll      OverlapPolicy = "buffer_all"
+	OverlapCancelOther    OverlapPolicy = "cancel_other"
+	OverlapTerminateOther OverlapPolicy = "terminate_other"
+	OverlapAllowAll       OverlapPolicy = "allow_all"
+)
+
+// OverlapPolicies lists every valid [OverlapPolicy].
+var OverlapPolicies = []OverlapPolicy{OverlapSkip, OverlapBufferOne, OverlapBufferAll, OverlapCancelOther, OverlapTerminateOther, OverlapAllowAll}
+
+// CronSchedule is a parsed [WorkflowOnCron].
+type CronSchedule struct {
+	Name     string
+	Schedule string
+	Timezone string
+	Jitter   time.Duration
+
+	// Window is nil if not set.
+	Window *TimeWindow
+
+	// Start and End are zero if not set.
+	Start time.Time
+	End   time.Time
+
+	Input   map[string]interface{}
+	Overlap OverlapPolicy
+}
+
+// ListSchedules returns the schedules of the workflow file: the schedule in `cron` if set, followed by the
+// schedules in `schedules`.
+func (o WorkflowOn) ListSchedules() []WorkflowOnCron {
+	res := []WorkflowOnCron{}
+
+	if o.Cron.Schedule != "" {
+		res = append(res, o.Cron)
+	}
+
+	return append(res, o.Schedules...)
+}
+
+// String returns the cron expression of the schedule, followed by its name and timezone if set.
+func (c WorkflowOnCron) String() string {
+	details := []string{}
+
+	if c.Name != "" {
+		details = append(details, c.Name)
+	}
+
+	if c.Timezone != "" {
+		details = append(details, c.Timezone)
+	}
+
+	if len(details) == 0 {
+		return c.Schedule
+	}
+
+	return fmt.Sprintf("%s (%s)", c.Schedule, strings.Join(details, ", "))
+}
+
+// Parse validates the schedule and returns the parsed schedule.
+func (c *WorkflowOnCron) Parse() (*CronSchedule, error) {
+	res := &CronSchedule{
+		Name:     c.Name,
+		Schedule: c.Schedule,
+		Timezone: c.Timezone,
+		Input:    c.Input,
+		Overlap:  c.Overlap,
+	}
+
+	if c.Schedule == "" {
+		return nil, fmt.Errorf("schedule is required")
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: must be an IANA timezone like America/New_York", c.Timezone)
+		}
+	}
+
+	validOverlap := c.Overlap == ""
+
+	for _, policy := range OverlapPolicies {
+		validOverlap = validOverlap || c.Overlap == policy
+	}
+
+	if !validOverlap {
+		return nil, fmt.Errorf("invalid overlap policy %q: must be one of %s, %s, %s, %s, %s or %s", c.Overlap,
+			OverlapSkip, OverlapBufferOne, OverlapBufferAll, OverlapCancelOther, OverlapTerminateOther, OverlapAllowAll)
+	}
+
+	var err error
+
+	if c.Window != "" {
+		if c.Schedule != string(RandomDaily) && c.Schedule != string(RandomWeekly) {
+			return nil, fmt.Errorf("window can only be set for %s and %s schedules", RandomDaily, RandomWeekly)
+		}
+
+		if res.Window, err = parseTimeWindow(c.Window); err != nil {
+			return nil, err
+		}
+	}
+
+	if res.Jitter, err = parseInterval("jitter", c.Jitter); err != nil {
+		return nil, err
+	}
+
+	if res.Start, err = parseTimestamp("start", c.Start); err != nil {
+		return nil, err
+	}
+
+	if res.End, err = parseTimestamp("end", c.End); err != nil {
+		return nil, err
+	}
+
+	if !res.Start.IsZero() && !res.End.IsZero() && !res.End.After(res.Start) {
+		return nil, fmt.Errorf("invalid end %q: must be after start", c.End)
+	}
+
+	return res, nil
+}
+
+// TimeWindow is a range of times of day, in minutes since midnight. The start is inclusive and the end is
+// exclusive.
+type TimeWindow struct {
+	Start int
+	End   int
+}
+
+// Minutes returns the length of the window in minutes. Windows which end before they start cross midnight.
+func (w TimeWindow) Minutes() int {
+	if w.End > w.Start {
+		return w.End - w.Start
+	}
+
+	return w.End + 24*60 - w.Start
+}
+
+func parseTimeWindow(window string) (*TimeWindow, error) {
+	start, end, found := strings.Cut(window, "-")
+
+	startTime, startErr := time.Parse("15:04", start)
+	endTime, endErr := time.Parse("15:04", end)
+
+	if !found || startErr != nil || endErr != nil {
+		return nil, fmt.Errorf("invalid window %q: must be a range of times like 09:00-17:00", window)
+	}
+
+	res := &TimeWindow{
+		Start: startTime.Hour()*60 + startTime.Minute(),
+		End:   endTime.Hour()*60 + endTime.Minute(),
+	}
+
+	if res.Start == res.End {
+		return nil, fmt.Errorf("invalid window %q: must end after it starts", window)
+	}
+
+	return res, nil
+}
+
+func parseTimestamp(field, timestamp string) (time.Time, error) {
+	if timestamp == "" {
+		return time.Time{}, nil
+	}
+
+	res, err := time.Parse(time.RFC3339, timestamp)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp like 2024-01-01T00:00:00Z", field, timestamp)
+	}
+
+	return res, nil
+}
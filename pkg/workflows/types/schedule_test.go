@@ -0,0 +1,211 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkflowOnCronParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		cron    WorkflowOnCron
+		want    *CronSchedule
+		wantErr string
+	}{
+		{
+			name: "cron expression",
+			cron: WorkflowOnCron{Schedule: "0 9 * * 1-5"},
+			want: &CronSchedule{Schedule: "0 9 * * 1-5"},
+		},
+		{
+			name: "every field",
+			cron: WorkflowOnCron{
+				Name:     "daily",
+				Schedule: string(RandomDaily),
+				Timezone: "America/New_York",
+				Jitter:   "5m",
+				Start:    "2024-01-01T00:00:00Z",
+				End:      "2024-06-01T00:00:00+02:00",
+				Input:    map[string]interface{}{"report": "daily"},
+				Overlap:  OverlapBufferOne,
+			},
+			want: &CronSchedule{
+				Name:     "daily",
+				Schedule: string(RandomDaily),
+				Timezone: "America/New_York",
+				Jitter:   5 * time.Minute,
+				Start:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:      time.Date(2024, 5, 31, 22, 0, 0, 0, time.UTC),
+				Input:    map[string]interface{}{"report": "daily"},
+				Overlap:  OverlapBufferOne,
+			},
+		},
+		{
+			name:    "missing schedule",
+			cron:    WorkflowOnCron{Timezone: "UTC"},
+			wantErr: "schedule is required",
+		},
+		{
+			name:    "invalid timezone",
+			cron:    WorkflowOnCron{Schedule: "@daily", Timezone: "EST5"},
+			wantErr: `invalid timezone "EST5"`,
+		},
+		{
+			name:    "invalid overlap policy",
+			cron:    WorkflowOnCron{Schedule: "@daily", Overlap: "queue"},
+			wantErr: `invalid overlap policy "queue"`,
+		},
+		{
+			name:    "invalid jitter",
+			cron:    WorkflowOnCron{Schedule: "@daily", Jitter: "soon"},
+			wantErr: "jitter",
+		},
+		{
+			name:    "invalid start",
+			cron:    WorkflowOnCron{Schedule: "@daily", Start: "2024-01-01"},
+			wantErr: `invalid start "2024-01-01": must be an RFC 3339 timestamp`,
+		},
+		{
+			name:    "end before start",
+			cron:    WorkflowOnCron{Schedule: "@daily", Start: "2024-01-02T00:00:00Z", End: "2024-01-01T00:00:00Z"},
+			wantErr: "must be after start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cron.Parse()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// times are compared as instants, as parsed times keep their offset
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("got range %s to %s, want %s to %s", got.Start, got.End, tt.want.Start, tt.want.End)
+			}
+
+			got.Start, got.End = tt.want.Start, tt.want.End
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowOnListSchedules(t *testing.T) {
+	on := WorkflowOn{
+		Cron:      WorkflowOnCron{Schedule: "@daily"},
+		Schedules: []WorkflowOnCron{{Name: "eu", Schedule: "0 9 * * *", Timezone: "Europe/Paris"}},
+	}
+
+	got := []string{}
+
+	for _, schedule := range on.ListSchedules() {
+		got = append(got, schedule.String())
+	}
+
+	if want := []string{"@daily", "0 9 * * * (eu, Europe/Paris)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := (WorkflowOn{}).ListSchedules(); len(got) != 0 {
+		t.Errorf("got %v, want no schedules", got)
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	tests := []struct {
+		window      string
+		want        *TimeWindow
+		wantMinutes int
+		wantErr     string
+	}{
+		{window: "09:00-17:00", want: &TimeWindow{Start: 540, End: 1020}, wantMinutes: 480},
+		{window: "9:30-10:00", want: &TimeWindow{Start: 570, End: 600}, wantMinutes: 30},
+		{window: "22:00-02:00", want: &TimeWindow{Start: 1320, End: 120}, wantMinutes: 240},
+		{window: "00:00-23:59", want: &TimeWindow{Start: 0, End: 1439}, wantMinutes: 1439},
+		{window: "09:00-09:00", wantErr: "must end after it starts"},
+		{window: "09:00", wantErr: "must be a range of times"},
+		{window: "9am-5pm", wantErr: "must be a range of times"},
+		{window: "24:00-01:00", wantErr: "must be a range of times"},
+		{window: " 09:00-17:00", wantErr: "must be a range of times"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			got, err := parseTimeWindow(tt.window)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			if got.Minutes() != tt.wantMinutes {
+				t.Errorf("got %d minutes, want %d", got.Minutes(), tt.wantMinutes)
+			}
+		})
+	}
+
+	// the zero window is the whole day
+	if got := (TimeWindow{}).Minutes(); got != 24*60 {
+		t.Errorf("got %d minutes for the zero window, want %d", got, 24*60)
+	}
+}
+
+func TestWorkflowOnCronParseWindow(t *testing.T) {
+	tests := []struct {
+		cron    WorkflowOnCron
+		wantErr string
+	}{
+		{cron: WorkflowOnCron{Schedule: string(RandomDaily), Window: "09:00-17:00"}},
+		{cron: WorkflowOnCron{Schedule: string(RandomWeekly), Window: "22:00-02:00"}},
+		{cron: WorkflowOnCron{Schedule: string(RandomHourly), Window: "09:00-17:00"}, wantErr: "window can only be set"},
+		{cron: WorkflowOnCron{Schedule: "0 9 * * *", Window: "09:00-17:00"}, wantErr: "window can only be set"},
+		{cron: WorkflowOnCron{Schedule: string(RandomDaily), Window: "17:00"}, wantErr: `invalid window "17:00"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cron.Schedule+" "+tt.cron.Window, func(t *testing.T) {
+			got, err := tt.cron.Parse()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Window == nil {
+				t.Error("expected the window to be parsed")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a location in a workflow file.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// source records where a workflow file was read from, and the position of each field in the file. Fields are keyed
+// by their path, like `jobs.my-job.steps[0].actionId`.
+type source struct {
+	filePath  string
+	positions map[string]Position
+}
+
+func newSource(filePath string, root *yaml.Node) *source {
+	res := &source{
+		filePath:  filePath,
+		positions: map[string]Position{},
+	}
+
+	if root != nil {
+		res.walk("", root)
+	}
+
+	return res
+}
+
+func (s *source) walk(path string, node *yaml.Node) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			s.walk(path, child)
+		}
+
+		return
+	}
+
+	s.positions[path] = Position{node.Line, node.Column}
+	s.walkChildren(path, node)
+}
+
+func (s *source) walkChildren(path string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			childPath := joinPath(path, key.Value)
+
+			// errors about a field point to its key
+			s.positions[childPath] = Position{key.Line, key.Column}
+
+			if val.Kind == yaml.MappingNode || val.Kind == yaml.SequenceNode {
+				s.walkChildren(childPath, val)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+			s.positions[itemPath] = Position{item.Line, item.Column}
+
+			if item.Kind == yaml.MappingNode || item.Kind == yaml.SequenceNode {
+				s.walkChildren(itemPath, item)
+			}
+		}
+	}
+}
+
+// position returns the position of the field at path. If the field does not exist in the file, the position of
+// its closest parent is returned.
+func (s *source) position(path string) Position {
+	if s == nil {
+		return Position{}
+	}
+
+	for {
+		if pos, ok := s.positions[path]; ok {
+			return pos
+		}
+
+		if path == "" {
+			return Position{}
+		}
+
+		path = parentPath(path)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func parentPath(path string) string {
+	i := strings.LastIndexAny(path, ".[")
+
+	if i < 0 {
+		return ""
+	}
+
+	return path[:i]
+}
+
+// findField returns the path and column of the field with the given key on a line, or an empty path if the
+// field is not found.
+func (s *source) findField(line int, key string) (string, int) {
+	if s == nil {
+		return "", 0
+	}
+
+	for path, pos := range s.positions {
+		if pos.Line == line && (path == key || strings.HasSuffix(path, "."+key)) {
+			return path, pos.Column
+		}
+	}
+
+	return "", 0
+}
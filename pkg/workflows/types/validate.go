@@ -0,0 +1,762 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+
+	"github.com/hatchet-dev/hatchet-workflows/internal/datautils"
+)
+
+// ValidationError is a single problem with a workflow file. Line and Column are 1-based, and are 0 if the
+// location of the problem is not known.
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+
+	// Warning is true for the problems found by [WorkflowFile.Lint], which do not prevent the file from running.
+	Warning bool
+}
+
+func (e *ValidationError) Error() string {
+	location := e.File
+
+	switch {
+	case e.Line > 0 && e.File == "":
+		location = fmt.Sprintf("line %d", e.Line)
+	case e.Line > 0 && e.Column > 0:
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	case e.Line > 0:
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+
+	parts := []string{}
+
+	for _, part := range []string{location, e.Path, e.Message} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ": ")
+}
+
+// Validate checks the workflow file for problems which would prevent it from running, like missing action IDs,
+// duplicate step IDs, unknown jobs in `needs` or templates referencing steps which have not run yet. It returns
+// a [ValidationError] for every problem found, combined with multierror.
+func (w *WorkflowFile) Validate() error {
+	v := &validator{file: w}
+
+	v.validateFile()
+
+	return v.errs
+}
+
+// ValidateFiles checks a set of workflow files which are loaded together for problems across files. Files whose
+// jobs run in a single workflow are registered as a Temporal workflow type by their name, and jobs by the name of
+// their file followed by their name, so file names must be unique. It does not validate each file; call
+// [WorkflowFile.Validate] for that.
+func ValidateFiles(files []*WorkflowFile) error {
+	var errs error
+
+	fileNames := map[string]bool{}
+
+	for _, file := range files {
+		v := &validator{file: file}
+
+		if fileNames[file.Name] {
+			v.addError("name", "duplicate workflow file name %s", file.Name)
+		}
+
+		fileNames[file.Name] = true
+
+		if v.errs != nil {
+			errs = multierror.Append(errs, v.errs)
+		}
+	}
+
+	return errs
+}
+
+type validator struct {
+	file *WorkflowFile
+	errs error
+}
+
+func (v *validator) addError(path string, format string, args ...interface{}) {
+	v.addProblem(false, path, format, args...)
+}
+
+func (v *validator) addWarning(path string, format string, args ...interface{}) {
+	v.addProblem(true, path, format, args...)
+}
+
+func (v *validator) addProblem(warning bool, path string, format string, args ...interface{}) {
+	pos := v.file.source.position(path)
+
+	v.errs = multierror.Append(v.errs, &ValidationError{
+		File:    v.file.FilePath(),
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+func (v *validator) validateFile() {
+	w := v.file
+
+	// file, job and schedule names are joined with / in the IDs of Temporal workflows and schedules, so a / in a
+	// name could make the IDs of two files collide
+	if w.Name == "" {
+		v.addError("name", "name is required")
+	} else if strings.Contains(w.Name, "/") {
+		v.addError("name", "name must not contain /")
+	}
+
+	if w.On.IdempotencyKey != "" {
+		if refs, err := datautils.TemplateReferences(w.On.IdempotencyKey); err != nil {
+			v.addError("on.idempotencyKey", "%v", err)
+		} else if referencesSecrets(refs) {
+			v.addError("on.idempotencyKey", "secrets can only be referenced in with values")
+		}
+	}
+
+	if w.On.ID != "" {
+		v.validateWorkflowID("on.id", w.On.ID)
+	}
+
+	for i, event := range w.On.Events {
+		eventPath := fmt.Sprintf("on.events[%d]", i)
+
+		if event.Name == "" {
+			v.addError(eventPath, "event name is required")
+		} else if _, err := path.Match(event.Name, ""); err != nil {
+			v.addError(eventPath, "invalid event name pattern %q: %v", event.Name, err)
+		}
+
+		if event.If != "" {
+			v.validateExpression(eventPath+".if", event.If, map[string]bool{}, map[string]bool{})
+		}
+	}
+
+	switch w.On.IDReusePolicy {
+	case "", AllowDuplicate, AllowDuplicateFailedOnly, RejectDuplicate, TerminateIfRunning:
+	default:
+		v.addError("on.idReusePolicy", "invalid id reuse policy %q: must be one of %s, %s, %s or %s", w.On.IDReusePolicy,
+			AllowDuplicate, AllowDuplicateFailedOnly, RejectDuplicate, TerminateIfRunning)
+	}
+
+	for _, name := range w.listInputNames() {
+		v.validateInput("inputs."+name, w.Inputs[name])
+	}
+
+	if w.On.Cron.Schedule != "" {
+		v.validateSchedule("on.cron", w.On.Cron)
+	}
+
+	for i, schedule := range w.On.Schedules {
+		v.validateSchedule(fmt.Sprintf("on.schedules[%d]", i), schedule)
+	}
+
+	// schedule names make the IDs of the Temporal schedules unique
+	scheduleNames := map[string]bool{}
+
+	for i, schedule := range w.On.ListSchedules() {
+		if !scheduleNames[schedule.Name] {
+			scheduleNames[schedule.Name] = true
+			continue
+		}
+
+		// the schedule in cron is listed first, so duplicates are always in schedules
+		path := fmt.Sprintf("on.schedules[%d]", i)
+
+		if w.On.Cron.Schedule != "" {
+			path = fmt.Sprintf("on.schedules[%d]", i-1)
+		}
+
+		if schedule.Name == "" {
+			v.addError(path+".name", "name is required when a file has more than one schedule")
+		} else {
+			v.addError(path+".name", "duplicate schedule name %s", schedule.Name)
+		}
+	}
+
+	if len(w.Jobs) == 0 {
+		v.addError("jobs", "at least one job is required")
+		return
+	}
+
+	jobNames := w.ListJobNames()
+	sort.Strings(jobNames)
+
+	unknownNeeds := false
+
+	for _, jobName := range jobNames {
+		for i, need := range w.Jobs[jobName].Needs {
+			if _, exists := w.Jobs[need]; !exists {
+				v.addError(fmt.Sprintf("jobs.%s.needs[%d]", jobName, i), "unknown job %s", need)
+				unknownNeeds = true
+			}
+		}
+	}
+
+	// unknown jobs are reported above, so the tree is only parsed to find cycles
+	if !unknownNeeds {
+		if _, err := ParseWorkflowTreeFromFile(*w); err != nil {
+			v.addError("jobs", "%v", err)
+		}
+	}
+
+	for _, jobName := range jobNames {
+		v.validateJob(jobName, w.Jobs[jobName])
+	}
+
+	// outputs of the file are rendered once every job has completed, from the results of the jobs
+	v.walkTemplates("outputs", w.Outputs, func(path string, refs [][]string) {
+		for _, ref := range refs {
+			switch ref[0] {
+			case "secrets":
+				v.addError(path, "secrets cannot be referenced in outputs")
+			case "steps", "needs":
+				v.addError(path, "outputs of the workflow file can only reference jobs under .jobs")
+			case "jobs":
+				if len(ref) < 2 {
+					continue
+				}
+
+				if _, exists := w.Jobs[ref[1]]; !exists {
+					v.addError(path, "references job %s, which does not exist", ref[1])
+				} else {
+					v.validateJobResultReference(path, ref)
+				}
+			}
+		}
+	})
+}
+
+// validateWorkflowID checks the template of the workflow IDs of job runs. The jobs of a file run at the same
+// time, so the template must reference the job name if the file has more than one job.
+func (v *validator) validateWorkflowID(path, tmplStr string) {
+	refs, err := datautils.TemplateReferences(tmplStr)
+
+	if err != nil {
+		v.addError(path, "%v", err)
+		return
+	}
+
+	if referencesSecrets(refs) {
+		v.addError(path, "secrets can only be referenced in with values")
+	}
+
+	referencesJob := false
+
+	for _, ref := range refs {
+		if ref[0] != "workflow" || len(ref) < 2 {
+			continue
+		}
+
+		switch ref[1] {
+		case "file", "key":
+		case "job":
+			referencesJob = true
+		default:
+			v.addError(path, "unknown field .workflow.%s: must be one of .workflow.file, .workflow.job or .workflow.key", ref[1])
+		}
+	}
+
+	if !referencesJob && len(v.file.Jobs) > 1 {
+		v.addError(path, "must reference .workflow.job, so that the jobs of the file have different workflow ids")
+	}
+}
+
+func (v *validator) validateSchedule(path string, schedule WorkflowOnCron) {
+	if _, err := schedule.Parse(); err != nil {
+		v.addError(path, "%v", err)
+	}
+
+	if strings.Contains(schedule.Name, "/") {
+		v.addError(path+".name", "schedule name must not contain /")
+	}
+
+	// static input is validated against the inputs of the file, as it is when a scheduled run starts
+	if _, err := v.file.ApplyInputs(schedule.Input); err != nil {
+		var merr *multierror.Error
+
+		if !errors.As(err, &merr) {
+			v.addError(path+".input", "%v", err)
+			return
+		}
+
+		for _, inputErr := range merr.Errors {
+			var ie *InputError
+
+			if errors.As(inputErr, &ie) {
+				v.addError(path+".input", "input %s: %s", ie.Input, ie.Message)
+			} else {
+				v.addError(path+".input", "%v", inputErr)
+			}
+		}
+	}
+}
+
+func (v *validator) validateInput(path string, input WorkflowInput) {
+	validType := input.Type == ""
+
+	for _, inputType := range InputTypes {
+		validType = validType || input.Type == inputType
+	}
+
+	if !validType {
+		v.addError(path+".type", "invalid type %q: must be one of %s, %s, %s, %s, %s or %s", input.Type, InputTypeString,
+			InputTypeNumber, InputTypeInteger, InputTypeBoolean, InputTypeObject, InputTypeArray)
+		return
+	}
+
+	if input.Type != "" {
+		for i, item := range input.Enum {
+			if msg := (WorkflowInput{Type: input.Type}).check(item); msg != "" {
+				v.addError(fmt.Sprintf("%s.enum[%d]", path, i), "%s", msg)
+			}
+		}
+	}
+
+	if input.Default != nil {
+		if msg := input.check(input.Default); msg != "" {
+			v.addError(path+".default", "%s", msg)
+		}
+	}
+}
+
+func (v *validator) validateJob(jobName string, job WorkflowJob) {
+	path := fmt.Sprintf("jobs.%s", jobName)
+
+	if strings.Contains(jobName, "/") {
+		v.addError(path, "job name must not contain /")
+	}
+
+	if _, err := job.GetTimeout(); err != nil {
+		v.addError(path+".timeout", "%v", err)
+	}
+
+	if job.Retries != nil {
+		if _, err := job.Retries.Parse(); err != nil {
+			v.addError(path+".retries", "%v", err)
+		}
+	}
+
+	needs := map[string]bool{}
+
+	for _, need := range job.Needs {
+		needs[need] = true
+	}
+
+	if job.If != "" {
+		v.validateExpression(path+".if", job.If, needs, map[string]bool{})
+	}
+
+	if len(job.Steps) == 0 {
+		v.addError(path+".steps", "at least one step is required")
+	}
+
+	// step ids must be unique across the job, including steps in parallel groups, compensations and failure
+	// handlers, as the outputs of every step are stored by id
+	usedIDs := map[string]bool{}
+
+	checkID := func(stepPath string, step WorkflowStep) {
+		if step.ID == "" {
+			return
+		}
+
+		if usedIDs[step.ID] {
+			v.addError(stepPath+".id", "duplicate step id %s", step.ID)
+		}
+
+		usedIDs[step.ID] = true
+	}
+
+	// stepIDs contains the steps of the job, excluding failure handlers
+	stepIDs := map[string]bool{}
+
+	for _, step := range job.Steps {
+		for _, jobStep := range append([]WorkflowStep{step}, step.Parallel...) {
+			if jobStep.ID != "" {
+				stepIDs[jobStep.ID] = true
+			}
+		}
+	}
+
+	// completed contains the steps which run before the current step, so their outputs can be referenced
+	completed := map[string]bool{}
+
+	for i, step := range job.Steps {
+		stepPath := fmt.Sprintf("%s.steps[%d]", path, i)
+
+		if !step.IsParallel() {
+			checkID(stepPath, step)
+			v.validateStep(stepPath, step, needs, completed, true)
+
+			if step.ID != "" {
+				completed[step.ID] = true
+			}
+
+			continue
+		}
+
+		v.validateGroup(stepPath, step)
+
+		// steps in a parallel group cannot see each other's outputs
+		groupCompleted := []string{}
+
+		for j, groupStep := range step.Parallel {
+			groupStepPath := fmt.Sprintf("%s.parallel[%d]", stepPath, j)
+
+			checkID(groupStepPath, groupStep)
+			v.validateStep(groupStepPath, groupStep, needs, completed, true)
+
+			if groupStep.ID != "" {
+				groupCompleted = append(groupCompleted, groupStep.ID)
+			}
+		}
+
+		for _, id := range groupCompleted {
+			completed[id] = true
+		}
+	}
+
+	for i, step := range job.Steps {
+		stepPath := fmt.Sprintf("%s.steps[%d]", path, i)
+
+		if step.Compensate != nil {
+			checkID(stepPath+".compensate", *step.Compensate)
+		}
+
+		for j, groupStep := range step.Parallel {
+			if groupStep.Compensate != nil {
+				checkID(fmt.Sprintf("%s.parallel[%d].compensate", stepPath, j), *groupStep.Compensate)
+			}
+		}
+	}
+
+	// failure handlers run after a step failed, so they can reference any step in the job, and the earlier
+	// failure handlers
+	failureCompleted := map[string]bool{}
+
+	for id := range stepIDs {
+		failureCompleted[id] = true
+	}
+
+	for i, step := range job.OnFailure {
+		stepPath := fmt.Sprintf("%s.onFailure[%d]", path, i)
+
+		if step.IsParallel() {
+			v.addError(stepPath+".parallel", "parallel groups are not supported in onFailure")
+			continue
+		}
+
+		checkID(stepPath, step)
+		v.validateStep(stepPath, step, needs, failureCompleted, false)
+
+		if step.ID != "" {
+			failureCompleted[step.ID] = true
+		}
+	}
+
+	// outputs are rendered once every step has succeeded, so they can reference any step in the job
+	v.walkTemplates(path+".outputs", job.Outputs, func(outputPath string, refs [][]string) {
+		if referencesSecrets(refs) {
+			v.addError(outputPath, "secrets cannot be referenced in outputs")
+		}
+
+		for _, ref := range refs {
+			if len(ref) < 2 {
+				continue
+			}
+
+			switch ref[0] {
+			case "steps":
+				if !stepIDs[ref[1]] {
+					v.addError(outputPath, "references step %s, which is not in the job", ref[1])
+				}
+			case "needs":
+				if !needs[ref[1]] {
+					v.addError(outputPath, "references job %s, which is not in needs", ref[1])
+				}
+			}
+		}
+	})
+}
+
+func (v *validator) validateGroup(path string, group WorkflowStep) {
+	unsupported := map[string]bool{
+		"id":         group.ID != "",
+		"actionId":   group.ActionID != "",
+		"timeout":    group.Timeout != "",
+		"if":         group.If != "",
+		"retries":    group.Retries != nil,
+		"with":       group.With != nil,
+		"compensate": group.Compensate != nil,
+		"forEach":    group.ForEach != nil,
+		"env":        group.Env != nil,
+	}
+
+	fields := make([]string, 0, len(unsupported))
+
+	for field, isSet := range unsupported {
+		if isSet {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		v.addError(path+"."+field, "%s is not supported on parallel groups", field)
+	}
+
+	for i, step := range group.Parallel {
+		if step.IsParallel() {
+			v.addError(fmt.Sprintf("%s.parallel[%d].parallel", path, i), "parallel groups cannot be nested")
+		}
+	}
+}
+
+// validateStep validates a step which runs an action. Templates in the step can reference the outputs of the
+// completed steps, and the compensation of the step can also reference the step itself.
+func (v *validator) validateStep(path string, step WorkflowStep, needs, completed map[string]bool, allowCompensate bool) {
+	if step.ActionID == "" {
+		v.addError(path+".actionId", "actionId is required")
+	} else if _, err := ParseActionID(step.ActionID); err != nil {
+		v.addError(path+".actionId", "%v", err)
+	}
+
+	if _, err := step.GetTimeout(); err != nil {
+		v.addError(path+".timeout", "%v", err)
+	}
+
+	if step.Retries != nil {
+		if _, err := step.Retries.Parse(); err != nil {
+			v.addError(path+".retries", "%v", err)
+		}
+	}
+
+	if step.If != "" {
+		v.validateExpression(path+".if", step.If, needs, completed)
+	}
+
+	if step.ForEach != nil {
+		if step.ForEach.Items == "" {
+			v.addError(path+".forEach.items", "items is required")
+		} else {
+			v.validateExpression(path+".forEach.items", step.ForEach.Items, needs, completed)
+		}
+
+		if step.ForEach.MaxConcurrency < 0 {
+			v.addError(path+".forEach.maxConcurrency", "maxConcurrency must be 0 or greater")
+		}
+	}
+
+	v.validateTemplates(path+".with", step.With, needs, completed)
+
+	if step.Compensate == nil {
+		return
+	}
+
+	compensatePath := path + ".compensate"
+
+	if !allowCompensate {
+		v.addError(compensatePath, "compensate is only supported on job steps")
+		return
+	}
+
+	if step.Compensate.IsParallel() {
+		v.addError(compensatePath+".parallel", "parallel groups are not supported in compensate")
+		return
+	}
+
+	compensateCompleted := map[string]bool{}
+
+	for id := range completed {
+		compensateCompleted[id] = true
+	}
+
+	if step.ID != "" {
+		compensateCompleted[step.ID] = true
+	}
+
+	v.validateStep(compensatePath, *step.Compensate, needs, compensateCompleted, false)
+}
+
+func (v *validator) validateTemplates(path string, value interface{}, needs, completed map[string]bool) {
+	v.walkTemplates(path, value, func(path string, refs [][]string) {
+		// only the secrets which are referenced by name are resolved, so `.secrets` can't be used as a map
+		for _, ref := range refs {
+			if len(ref) == 1 && ref[0] == "secrets" {
+				v.addError(path, "secrets must be referenced by name, like .secrets.NAME")
+				break
+			}
+		}
+
+		v.validateReferences(path, refs, needs, completed)
+	})
+}
+
+// walkTemplates calls check with the references of each template in value, which may be a string or a map or list
+// of templates. Templates which cannot be parsed are reported as errors.
+func (v *validator) walkTemplates(path string, value interface{}, check func(path string, refs [][]string)) {
+	switch val := value.(type) {
+	case string:
+		refs, err := datautils.TemplateReferences(val)
+
+		if err != nil {
+			v.addError(path, "%v", err)
+			return
+		}
+
+		check(path, refs)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+
+		for key := range val {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			v.walkTemplates(joinPath(path, key), val[key], check)
+		}
+	case []interface{}:
+		for i, item := range val {
+			v.walkTemplates(fmt.Sprintf("%s[%d]", path, i), item, check)
+		}
+	}
+}
+
+func (v *validator) validateExpression(path string, expression string, needs, completed map[string]bool) {
+	refs, err := datautils.ExpressionReferences(expression)
+
+	if err != nil {
+		v.addError(path, "%v", err)
+		return
+	}
+
+	// conditions and forEach items are evaluated by the workflow, so secrets would be written to its history
+	if referencesSecrets(refs) {
+		v.addError(path, "secrets can only be referenced in with values")
+	}
+
+	v.validateReferences(path, refs, needs, completed)
+}
+
+func referencesSecrets(refs [][]string) bool {
+	for _, ref := range refs {
+		if ref[0] == "secrets" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateReferences checks that references to `.steps.<id>` and `.needs.<job>` point to steps which run
+// before the current step and jobs which are needed.
+func (v *validator) validateReferences(path string, refs [][]string, needs, completed map[string]bool) {
+	for _, ref := range refs {
+		if len(ref) < 2 {
+			continue
+		}
+
+		switch ref[0] {
+		case "steps":
+			if !completed[ref[1]] {
+				v.addError(path, "references step %s, which does not run before this step", ref[1])
+			}
+		case "needs":
+			if !needs[ref[1]] {
+				v.addError(path, "references job %s, which is not in needs", ref[1])
+			} else {
+				v.validateJobResultReference(path, ref)
+			}
+		}
+	}
+}
+
+// validateJobResultReference checks that a reference to the result of a job, like `.needs.<job>.outputs.<name>` or
+// `.jobs.<job>.steps.<id>`, points to an output or step of the job. The job must exist.
+func (v *validator) validateJobResultReference(path string, ref []string) {
+	if len(ref) < 4 {
+		return
+	}
+
+	job := v.file.Jobs[ref[1]]
+
+	switch ref[2] {
+	case "steps":
+		if !hasStep(job, ref[3]) {
+			v.addError(path, "references step %s of job %s, which does not exist", ref[3], ref[1])
+		}
+	case "outputs":
+		if _, exists := job.Outputs[ref[3]]; !exists {
+			v.addError(path, "references output %s of job %s, which does not exist", ref[3], ref[1])
+		}
+	}
+}
+
+func hasStep(job WorkflowJob, stepID string) bool {
+	for _, step := range flattenSteps(job.Steps) {
+		if step.ID == stepID {
+			return true
+		}
+	}
+
+	return false
+}
+
+var errorLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+var unknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type`)
+
+// toParseError converts errors from the yaml decoder into validation errors with the line of the problem, and
+// the column and path of unknown fields. Errors without a line are returned as a single validation error.
+func toParseError(filePath string, src *source, err error) error {
+	messages := []string{err.Error()}
+
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+
+	var allErrs error
+
+	for _, message := range messages {
+		validationErr := &ValidationError{
+			File:    filePath,
+			Message: message,
+		}
+
+		if matches := errorLineRegex.FindStringSubmatch(message); matches != nil {
+			validationErr.Line, _ = strconv.Atoi(matches[1])
+			validationErr.Message = matches[2]
+
+			if fieldMatches := unknownFieldRegex.FindStringSubmatch(matches[2]); fieldMatches != nil {
+				validationErr.Message = fmt.Sprintf("unknown field %s", fieldMatches[1])
+				validationErr.Path, validationErr.Column = src.findField(validationErr.Line, fieldMatches[1])
+			}
+		}
+
+		allErrs = multierror.Append(allErrs, validationErr)
+	}
+
+	return allErrs
+}
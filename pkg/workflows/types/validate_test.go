@@ -0,0 +1,424 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// validationProblems parses and validates the file, and returns each problem as "path: message".
+func validationProblems(t *testing.T, yamlStr string) []string {
+	t.Helper()
+
+	file, err := ParseYAML(context.Background(), []byte(yamlStr), WithStrictFields())
+
+	if err == nil {
+		err = file.Validate()
+	}
+
+	return problems(err)
+}
+
+func problems(err error) []string {
+	res := []string{}
+
+	if err == nil {
+		return res
+	}
+
+	errs := []error{err}
+
+	var merr *multierror.Error
+
+	if errors.As(err, &merr) {
+		errs = merr.Errors
+	}
+
+	for _, err := range errs {
+		var verr *ValidationError
+
+		if errors.As(err, &verr) {
+			res = append(res, verr.Path+": "+verr.Message)
+		} else {
+			res = append(res, err.Error())
+		}
+	}
+
+	return res
+}
+
+// checkProblems fails the test unless every problem contains one of the wanted substrings, in order.
+func checkProblems(t *testing.T, got, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got problems %q, want %q", got, want)
+	}
+
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("got problem %q, want it to contain %q", got[i], want[i])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "valid file",
+			yaml: `
+name: valid
+on:
+  events: [user:create]
+jobs:
+  greet:
+    steps:
+      - id: create
+        actionId: slack:create-channel
+        timeout: 10s
+        with:
+          name: "{{ .username }}"
+        compensate:
+          id: deleteChannel
+          actionId: slack:delete-channel
+          timeout: 10s
+          with:
+            channel: "{{ .steps.create.outputs.id }}"
+      - id: send
+        actionId: slack:send-message
+        timeout: 10s
+        with:
+          channel: "{{ .steps.create.outputs.id }}"
+    onFailure:
+      - id: notify
+        actionId: slack:send-message
+        timeout: 10s
+        with:
+          message: "{{ .failure.error }}"
+      - id: notifyAgain
+        actionId: slack:send-message
+        timeout: 10s
+        with:
+          message: "{{ .steps.notify.outputs.ts }}"
+`,
+		},
+		{
+			name: "missing action id and duplicate step ids",
+			yaml: `
+name: invalid
+jobs:
+  greet:
+    steps:
+      - id: a
+        timeout: 10s
+      - id: a
+        actionId: slack:send-message
+        timeout: 10s
+`,
+			want: []string{
+				"jobs.greet.steps[0].actionId: actionId is required",
+				"jobs.greet.steps[1].id: duplicate step id a",
+			},
+		},
+		{
+			name: "failure handlers and compensations share step ids with the job",
+			yaml: `
+name: duplicate-handlers
+jobs:
+  greet:
+    steps:
+      - id: create
+        actionId: slack:create-channel
+        timeout: 10s
+        compensate:
+          id: create
+          actionId: slack:delete-channel
+          timeout: 10s
+      - parallel:
+          - id: send
+            actionId: slack:send-message
+            timeout: 10s
+            compensate:
+              id: undo
+              actionId: slack:delete-message
+              timeout: 10s
+    onFailure:
+      - id: send
+        actionId: slack:send-message
+        timeout: 10s
+      - id: undo
+        actionId: slack:send-message
+        timeout: 10s
+`,
+			want: []string{
+				"jobs.greet.steps[0].compensate.id: duplicate step id create",
+				"jobs.greet.onFailure[0].id: duplicate step id send",
+				"jobs.greet.onFailure[1].id: duplicate step id undo",
+			},
+		},
+		{
+			name: "unknown needs and cycles",
+			yaml: `
+name: needs
+jobs:
+  a:
+    needs: [b]
+    steps:
+      - actionId: a:b
+        timeout: 1s
+  b:
+    needs: [a, c]
+    steps:
+      - actionId: a:b
+        timeout: 1s
+`,
+			want: []string{
+				"jobs.b.needs[1]: unknown job c",
+			},
+		},
+		{
+			name: "templates reference steps which have not run",
+			yaml: `
+name: references
+jobs:
+  a:
+    steps:
+      - id: first
+        actionId: a:b
+        timeout: 1s
+        with:
+          value: "{{ .steps.second.outputs.id }}"
+      - id: second
+        actionId: a:b
+        timeout: 1s
+`,
+			want: []string{
+				"jobs.a.steps[0].with.value: references step second",
+			},
+		},
+		{
+			name: "references to the outputs and steps of other jobs",
+			yaml: `
+name: outputs
+jobs:
+  a:
+    steps:
+      - id: lookup
+        actionId: a:b
+        timeout: 1s
+    outputs:
+      userId: "{{ .steps.lookup.outputs.id }}"
+  b:
+    needs: [a]
+    steps:
+      - actionId: a:b
+        timeout: 1s
+        with:
+          user: "{{ .needs.a.outputs.userId }}"
+          email: "{{ .needs.a.outputs.email }}"
+          raw: "{{ .needs.a.steps.lookup.outputs.id }}"
+          missing: "{{ .needs.a.steps.find.outputs.id }}"
+outputs:
+  userId: "{{ .jobs.a.outputs.userId }}"
+  email: "{{ .jobs.a.outputs.email }}"
+  status: "{{ .jobs.b.status }}"
+  channel: "{{ .jobs.c.outputs.channel }}"
+`,
+			want: []string{
+				"jobs.b.steps[0].with.email: references output email of job a, which does not exist",
+				"jobs.b.steps[0].with.missing: references step find of job a, which does not exist",
+				"outputs.channel: references job c, which does not exist",
+				"outputs.email: references output email of job a, which does not exist",
+			},
+		},
+		{
+			name: "invalid timeouts and retries",
+			yaml: `
+name: timeouts
+jobs:
+  a:
+    timeout: soon
+    steps:
+      - actionId: a:b
+        timeout: 1s
+        retries:
+          maxAttempts: -1
+`,
+			want: []string{
+				"jobs.a.timeout",
+				"jobs.a.steps[0].retries: invalid maxAttempts -1",
+			},
+		},
+		{
+			name: "secrets",
+			yaml: `
+name: secrets
+jobs:
+  a:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+        with:
+          token: "{{ .secrets.TOKEN }}"
+          header: "Bearer {{ .secrets.TOKEN | b64enc }}"
+          index: '{{ index .secrets "TOKEN" }}'
+          all: "${{ .secrets }}"
+          range: "{{ range $name, $value := .secrets }}{{ $name }}{{ end }}"
+`,
+			want: []string{
+				"jobs.a.steps[0].with.all: secrets must be referenced by name",
+				"jobs.a.steps[0].with.index: secrets must be referenced by name",
+				"jobs.a.steps[0].with.range: secrets must be referenced by name",
+			},
+		},
+		{
+			name: "slashes in names",
+			yaml: `
+name: reports/daily
+on:
+  schedules:
+    - name: eu/west
+      schedule: "0 3 * * *"
+jobs:
+  send/email:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+`,
+			want: []string{
+				"name: name must not contain /",
+				"on.schedules[0].name: schedule name must not contain /",
+				"jobs.send/email: job name must not contain /",
+			},
+		},
+		{
+			name: "workflow id template",
+			yaml: `
+name: ids
+on:
+  id: "{{ .workflow.file }}/{{ .workflow.run }}/{{ .secrets.TOKEN }}"
+jobs:
+  a:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+  b:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+`,
+			want: []string{
+				"on.id: secrets can only be referenced in with values",
+				"on.id: unknown field .workflow.run",
+				"on.id: must reference .workflow.job",
+			},
+		},
+		{
+			name: "workflow id template of a single job",
+			yaml: `
+name: ids
+on:
+  id: "{{ .tenantId }}-{{ .workflow.key }}"
+jobs:
+  a:
+    steps:
+      - actionId: a:b
+        timeout: 1s
+`,
+		},
+		{
+			name: "unknown fields",
+			yaml: `
+name: unknown
+jobs:
+  a:
+    stepz: []
+`,
+			want: []string{
+				"jobs.a.stepz: unknown field stepz",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkProblems(t, validationProblems(t, tt.yaml), tt.want)
+		})
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	parse := func(yamlStr string) *WorkflowFile {
+		file, err := ParseYAML(context.Background(), []byte(yamlStr))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return &file
+	}
+
+	independent := func(name string, jobs ...string) *WorkflowFile {
+		yamlStr := "name: " + name + "\njobs:\n"
+
+		for _, job := range jobs {
+			yamlStr += "  " + job + ":\n    steps:\n      - actionId: a:b\n"
+		}
+
+		return parse(yamlStr)
+	}
+
+	dependent := func(name string) *WorkflowFile {
+		return parse("name: " + name + `
+jobs:
+  first:
+    steps:
+      - actionId: a:b
+  second:
+    needs: [first]
+    steps:
+      - actionId: a:b
+`)
+	}
+
+	tests := []struct {
+		name  string
+		files []*WorkflowFile
+		want  []string
+	}{
+		{
+			name:  "unique names",
+			files: []*WorkflowFile{independent("a", "x", "y"), independent("b", "z"), dependent("c")},
+		},
+		{
+			name:  "duplicate file names",
+			files: []*WorkflowFile{independent("a", "x"), independent("a", "y")},
+			want:  []string{"name: duplicate workflow file name a"},
+		},
+		{
+			name:  "duplicate job names",
+			files: []*WorkflowFile{independent("a", "x"), independent("b", "x", "y")},
+		},
+		{
+			name:  "job named like a file with job dependencies",
+			files: []*WorkflowFile{dependent("c"), independent("b", "c")},
+		},
+		{
+			name:  "job names of files with job dependencies",
+			files: []*WorkflowFile{dependent("c"), dependent("d")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkProblems(t, problems(ValidateFiles(tt.files)), tt.want)
+		})
+	}
+}